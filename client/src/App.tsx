@@ -26,7 +26,7 @@ import { FlowShow } from './pages/flow'
 import { WizardList, WizardShow } from './pages/wizard'
 import { dataProvider as launchrDataProvider } from './rest-data-provider'
 import { ThemeProvider } from './ThemeProvider'
-import { getApiUrl } from './utils/app-urls-resolver'
+import { getApiUrl, getBasePath } from './utils/app-urls-resolver'
 import { setCustomisation } from './utils/page-customisation'
 
 const apiUrl = getApiUrl()
@@ -50,7 +50,7 @@ export function App() {
   }
 
   return (
-    <BrowserRouter>
+    <BrowserRouter basename={getBasePath()}>
       <ThemeProvider>
         <Refine
           dataProvider={{
@@ -11,15 +11,37 @@ import TerminalBox from './TerminalBox'
 import { Fab, Stack } from '@mui/material'
 import { splitRunId } from '../utils/helpers'
 
+type StreamData = components['schemas']['ActionRunStreamData']
+
+// The server sends the output written since the previous message,
+// a chunk from the beginning of the stream replaces it.
+const appendStreamChunks = (streams: StreamData[], chunks: StreamData[]) => {
+  const result = [...streams]
+  for (const chunk of chunks) {
+    const i = result.findIndex((stream) => stream.type === chunk.type)
+    if (i === -1) {
+      result.push(chunk)
+    } else if (chunk.offset === 0) {
+      result[i] = chunk
+    } else if (chunk.count > 0) {
+      const stream = result[i]
+      result[i] = {
+        ...stream,
+        content: stream.content + chunk.content,
+        count: chunk.offset + chunk.count - stream.offset,
+      }
+    }
+  }
+  return result
+}
+
 interface IStatusBoxProcessProps {
   ri: components['schemas']['ActionRunInfo']
   actionId: string
 }
 
 const StatusBoxProcess: FC<IStatusBoxProcessProps> = ({ ri, actionId }) => {
-  const [streams, setStreams] = useState<
-    components['schemas']['ActionRunStreamData'][]
-  >([])
+  const [streams, setStreams] = useState<StreamData[]>([])
   const apiUrl = useApiUrl()
   const { mutateAsync } = useCustomMutation()
 
@@ -37,7 +59,8 @@ const StatusBoxProcess: FC<IStatusBoxProcessProps> = ({ ri, actionId }) => {
     onLiveEvent: ({ payload, type }) => {
       if (payload?.data?.action === ri.id) {
         if (type === 'send-process' && payload?.data?.data) {
-          setStreams(payload.data.data)
+          const chunks: StreamData[] = payload.data.data
+          setStreams((prev) => appendStreamChunks(prev, chunks))
         }
 
         if (type === 'send-process-finished') {
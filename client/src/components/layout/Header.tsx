@@ -58,13 +58,13 @@ export const ThemedHeaderV2: FC<RefineThemedLayoutV2HeaderProps> = () => {
         <Box sx={{ flexGrow: 1 }} />
         <Stack direction="row" spacing={1} alignItems="center">
           <Tooltip title="Wizard experiment">
-            <IconButton href="/wizard" size="small" color="inherit">
+            <IconButton href="wizard" size="small" color="inherit">
               <AssistantIcon />
             </IconButton>
           </Tooltip>
 
           <Tooltip title="Actions list">
-            <IconButton href="/list" size="small" color="inherit">
+            <IconButton href="list" size="small" color="inherit">
               <ListIcon />
             </IconButton>
           </Tooltip>
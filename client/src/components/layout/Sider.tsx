@@ -307,7 +307,7 @@ export const ThemedSiderV2: FC<RefineThemedLayoutV2SiderProps> = ({
         arrow
       >
         <ListItemButton
-          href="/"
+          href="./"
           selected={selectedKey === '/'}
           onClick={() => {
             setMobileSiderOpen(false)
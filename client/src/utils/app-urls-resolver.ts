@@ -2,6 +2,12 @@ const isProductionMode = import.meta.env.MODE === 'production'
 const addDefaultPort = (protocol: string) =>
   protocol === 'https:' ? ':443' : ':80'
 
+// The server sets the base href to the path the app is served on,
+// e.g. "/launchr/" behind a reverse proxy.
+export function getBasePath() {
+  return new URL(document.baseURI).pathname.replace(/\/$/, '')
+}
+
 export function getApiUrl() {
   let url = import.meta.env.VITE_API_URL
 
@@ -13,7 +19,7 @@ export function getApiUrl() {
       url += addDefaultPort(location.protocol)
     }
 
-    url += '/api'
+    url += getBasePath() + '/api'
   }
 
   return url
@@ -30,7 +36,7 @@ export function getWebSocketUrl() {
       url += addDefaultPort(location.protocol)
     }
 
-    url += '/ws'
+    url += getBasePath() + '/ws'
   }
 
   return url
@@ -3,5 +3,7 @@ import { defineConfig } from 'vite'
 import env from 'vite-plugin-env-compatible'
 
 export default defineConfig({
+  // Assets are resolved relative to the base href set by the server.
+  base: './',
   plugins: [react(), env()],
 })
@@ -0,0 +1,164 @@
+package web
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/launchrctl/launchr"
+)
+
+const (
+	defaultInstance = "default"
+	instancesDir    = "instances"
+)
+
+var instanceNameRegexp = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]*$`)
+
+func validateInstanceName(name string) error {
+	if !instanceNameRegexp.MatchString(name) {
+		return fmt.Errorf("invalid instance name %q, only letters, digits, \".\", \"_\" and \"-\" are allowed", name)
+	}
+	return nil
+}
+
+// instanceDir returns the temporary directory of the server instance.
+// The default instance keeps the directory of the previous versions.
+func (p *Plugin) instanceDir(name string) string {
+	if name == defaultInstance {
+		return p.cfg.Path(pluginName)
+	}
+	return p.cfg.Path(pluginName, instancesDir, name)
+}
+
+// instanceNames returns the names of the instances having a temporary directory.
+func (p *Plugin) instanceNames() ([]string, error) {
+	names := []string{defaultInstance}
+	entries, err := os.ReadDir(p.cfg.Path(pluginName, instancesDir))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("can't read instances: %w", err)
+	}
+	for _, e := range entries {
+		if e.IsDir() && validateInstanceName(e.Name()) == nil {
+			names = append(names, e.Name())
+		}
+	}
+	slices.Sort(names[1:])
+	return names, nil
+}
+
+// title returns the name of the instance for the messages.
+func (f webFlags) title() string {
+	if f.Name == defaultInstance {
+		return "web UI"
+	}
+	return fmt.Sprintf("web UI %q", f.Name)
+}
+
+// forInstance returns the flags to operate another instance.
+func (p *Plugin) forInstance(f webFlags, name string) webFlags {
+	f.Name = name
+	f.PluginDir = p.instanceDir(name)
+	return f
+}
+
+// instanceStatus is a row of the list operation.
+type instanceStatus struct {
+	Name string `json:"name"`
+	webStatus
+	WorkDir string `json:"workDir,omitempty"`
+}
+
+// listWeb prints all server instances with their state.
+func (p *Plugin) listWeb(webOpts webFlags, jsonOut bool) error {
+	names, err := p.instanceNames()
+	if err != nil {
+		return err
+	}
+
+	list := make([]instanceStatus, 0, len(names))
+	for _, name := range names {
+		inst := p.forInstance(webOpts, name)
+		st, err := getWebStatus(filepath.Join(inst.PluginDir, pidFile), inst)
+		if err != nil {
+			return err
+		}
+		ri, _ := getServerInfo(inst.PluginDir)
+		if ri == nil && !st.Running {
+			continue
+		}
+		row := instanceStatus{Name: name, webStatus: st}
+		if ri != nil {
+			row.WorkDir = ri.WorkDir
+		}
+		list = append(list, row)
+	}
+
+	if jsonOut {
+		out, err := json.MarshalIndent(list, "", "  ")
+		if err != nil {
+			return err
+		}
+		launchr.Term().Println(string(out))
+		return nil
+	}
+
+	if len(list) == 0 {
+		launchr.Term().Info().Println("There are no web UI instances.")
+		return nil
+	}
+	w := tabwriter.NewWriter(launchr.Term(), 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "NAME\tSTATUS\tPID\tURL\tWORKDIR")
+	for _, row := range list {
+		status := "stopped"
+		switch {
+		case row.Healthy:
+			status = "running"
+		case row.Running:
+			status = "unhealthy"
+		}
+		pid := "-"
+		if row.PID != 0 && row.Running {
+			pid = strconv.Itoa(row.PID)
+		}
+		addr := row.URL
+		if row.Socket != "" {
+			addr = "unix://" + row.Socket
+		}
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", row.Name, status, pid, addr, row.WorkDir)
+	}
+	return w.Flush()
+}
+
+// stopAllWeb stops all running instances.
+func (p *Plugin) stopAllWeb(webOpts webFlags) error {
+	names, err := p.instanceNames()
+	if err != nil {
+		return err
+	}
+	var errs []error
+	stopped := 0
+	for _, name := range names {
+		inst := p.forInstance(webOpts, name)
+		pidPath := filepath.Join(inst.PluginDir, pidFile)
+		if _, ok := pidFileInfo(pidPath); !ok {
+			if ri, _ := getServerInfo(inst.PluginDir); ri == nil {
+				continue
+			}
+		}
+		stopped++
+		if err = stopWeb(pidPath, inst); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", inst.title(), err))
+		}
+	}
+	if stopped == 0 {
+		launchr.Term().Warning().Println("There is no active Web UI that can be stopped.")
+	}
+	return errors.Join(errs...)
+}
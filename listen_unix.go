@@ -0,0 +1,13 @@
+//go:build unix
+
+package web
+
+import (
+	"errors"
+	"syscall"
+)
+
+// isAddrInUse checks if the listener failed because the port is taken.
+func isAddrInUse(err error) bool {
+	return errors.Is(err, syscall.EADDRINUSE)
+}
@@ -0,0 +1,15 @@
+//go:build windows
+
+package web
+
+import (
+	"errors"
+
+	"golang.org/x/sys/windows"
+)
+
+// isAddrInUse checks if the listener failed because the port is taken.
+// Windows sockets report WSAEADDRINUSE, the socket may also be forbidden by the exclusive port ranges.
+func isAddrInUse(err error) bool {
+	return errors.Is(err, windows.WSAEADDRINUSE) || errors.Is(err, windows.WSAEACCES)
+}
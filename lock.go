@@ -0,0 +1,146 @@
+package web
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/launchrctl/launchr"
+)
+
+const (
+	lockFilename = "web.lock"
+	// lockFdEnvVar passes the descriptor of the lock held by the parent process, the lock file path on windows.
+	lockFdEnvVar = launchr.EnvVar("web_lock_fd")
+)
+
+// errInstanceLocked is returned when the lock is held by another process.
+var errInstanceLocked = errors.New("instance is locked by another process")
+
+// lockInfo describes the server holding the lock.
+type lockInfo struct {
+	PID       int       `json:"pid"`
+	Port      int       `json:"port,omitempty"`
+	Socket    string    `json:"socket,omitempty"`
+	StartedAt time.Time `json:"startedAt"`
+	WorkDir   string    `json:"workDir,omitempty"`
+}
+
+// instanceLock is an advisory lock of the server instance held for the server lifetime.
+// The system releases the lock when the process exits, so a crashed server can't leave it stale.
+// The file is never removed, otherwise another process could lock a new file while the old one is still held.
+type instanceLock struct {
+	f *os.File
+}
+
+// lockInstance takes the lock of the instance or adopts the lock passed by the parent process.
+// It returns an error describing the running server if the lock is held.
+func lockInstance(webOpts webFlags) (*instanceLock, error) {
+	if l := inheritedLock(); l != nil {
+		return l, nil
+	}
+
+	if err := launchr.EnsurePath(webOpts.PluginDir); err != nil {
+		return nil, fmt.Errorf("can't create plugin temporary directory")
+	}
+	path := filepath.Join(webOpts.PluginDir, lockFilename)
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0600) //nolint G304 // Path is clean.
+	if err != nil {
+		return nil, fmt.Errorf("can't open lock file: %w", err)
+	}
+	// The stop and restart commands wait until the server exits, the lock isn't retried.
+	err = lockFile(f)
+	if errors.Is(err, errInstanceLocked) {
+		_ = f.Close()
+		return nil, alreadyRunningError(webOpts)
+	}
+	if err != nil {
+		_ = f.Close()
+		return nil, fmt.Errorf("can't lock %s: %w", path, err)
+	}
+
+	l := &instanceLock{f: f}
+	info := lockInfo{PID: os.Getpid(), StartedAt: time.Now()}
+	info.WorkDir, _ = os.Getwd()
+	if err = l.update(info); err != nil {
+		l.Release()
+		return nil, err
+	}
+	return l, nil
+}
+
+// update replaces the information about the server holding the lock.
+func (l *instanceLock) update(info lockInfo) error {
+	data, err := json.Marshal(info)
+	if err != nil {
+		return err
+	}
+	if err = l.f.Truncate(0); err != nil {
+		return fmt.Errorf("can't write lock file: %w", err)
+	}
+	if _, err = l.f.WriteAt(data, 0); err != nil {
+		return fmt.Errorf("can't write lock file: %w", err)
+	}
+	return nil
+}
+
+// Release releases the lock if no other process shares it.
+func (l *instanceLock) Release() {
+	if l.f == nil {
+		return
+	}
+	_ = l.f.Close()
+	l.f = nil
+}
+
+// readInstanceLock returns the information about the server and whether the lock is still held.
+// The information of the released lock is left by a stopped or crashed server.
+func readInstanceLock(dir string) (*lockInfo, bool) {
+	f, err := os.Open(filepath.Join(dir, lockFilename)) //nolint G304 // Path is clean.
+	if err != nil {
+		return nil, false
+	}
+	defer f.Close()
+
+	var info *lockInfo
+	if data, err := io.ReadAll(f); err == nil && len(data) > 0 {
+		info = &lockInfo{}
+		if err = json.Unmarshal(data, info); err != nil {
+			info = nil
+		}
+	}
+	// The lock isn't taken to check it, a server starting meanwhile must not find it locked.
+	return info, isLockHeld(f, info)
+}
+
+// isLockOwnerRunning checks if the process written to the lock is running.
+// The pid may be reused by another process after the server is gone, the check is used if the lock can't be inspected.
+func isLockOwnerRunning(info *lockInfo) bool {
+	return info != nil && info.PID != 0 && isProcessRunning(info.PID)
+}
+
+// alreadyRunningError describes the server holding the lock of the instance.
+func alreadyRunningError(webOpts webFlags) error {
+	info, _ := readInstanceLock(webOpts.PluginDir)
+	addr := ""
+	if ri, _ := getServerInfo(webOpts.PluginDir); ri != nil && ri.URL != "" {
+		addr = ri.address()
+	} else if info != nil && info.Socket != "" {
+		addr = "unix://" + info.Socket
+	} else if info != nil && info.Port != 0 {
+		addr = fmt.Sprintf("port %d", info.Port)
+	}
+
+	msg := "another " + webOpts.title() + " is already running"
+	if addr != "" {
+		msg += " at " + addr
+	}
+	if info != nil && info.PID != 0 {
+		msg += fmt.Sprintf(" (pid: %d)", info.PID)
+	}
+	return fmt.Errorf("%s\nPlease stop the existing server or use --name to start another instance", msg)
+}
@@ -0,0 +1,41 @@
+//go:build linux
+
+package web
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"golang.org/x/sys/unix"
+)
+
+// isLockHeld looks for the lock of the file in /proc/locks.
+func isLockHeld(f *os.File, info *lockInfo) bool {
+	var st unix.Stat_t
+	if err := unix.Fstat(int(f.Fd()), &st); err != nil {
+		return isLockOwnerRunning(info)
+	}
+	locks, err := os.Open("/proc/locks")
+	if err != nil {
+		return isLockOwnerRunning(info)
+	}
+	defer locks.Close()
+
+	// The lines look like "1: FLOCK  ADVISORY  WRITE 1234 08:02:5678 0 EOF",
+	// the device numbers are hexadecimal and the inode is decimal.
+	id := fmt.Sprintf("%02x:%02x:%d", unix.Major(st.Dev), unix.Minor(st.Dev), st.Ino)
+	sc := bufio.NewScanner(locks)
+	for sc.Scan() {
+		fields := strings.Fields(sc.Text())
+		// Blocked requests are marked with "->" and don't hold the lock.
+		if len(fields) < 6 || fields[1] != "FLOCK" {
+			continue
+		}
+		if fields[5] == id {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,10 @@
+//go:build !linux
+
+package web
+
+import "os"
+
+// isLockHeld checks the process holding the lock, the lock can't be inspected without taking it.
+func isLockHeld(_ *os.File, info *lockInfo) bool {
+	return isLockOwnerRunning(info)
+}
@@ -0,0 +1,86 @@
+package web
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestReadInstanceLock(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		setup   func(t *testing.T, dir string)
+		expInfo bool
+		expHeld bool
+		// The released lock is detected by inspecting the lock, other systems check the pid only.
+		linuxOnly bool
+	}{
+		{"no lock file", func(*testing.T, string) {}, false, false, false},
+		{"empty lock file", func(t *testing.T, dir string) { writeLockFile(t, dir, "") }, false, false, false},
+		{"invalid lock file", func(t *testing.T, dir string) { writeLockFile(t, dir, "{") }, false, false, false},
+		{"stale lock file", func(t *testing.T, dir string) { writeLockFile(t, dir, `{"pid":0,"port":8080}`) }, true, false, false},
+		{"held lock", func(t *testing.T, dir string) {
+			l, err := lockInstance(webFlags{PluginDir: dir})
+			if err != nil {
+				t.Fatal(err)
+			}
+			t.Cleanup(l.Release)
+		}, true, true, false},
+		{"released lock", func(t *testing.T, dir string) {
+			l, err := lockInstance(webFlags{PluginDir: dir})
+			if err != nil {
+				t.Fatal(err)
+			}
+			l.Release()
+		}, true, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if tt.linuxOnly && runtime.GOOS != "linux" {
+				t.Skip("the lock is inspected on linux only")
+			}
+			dir := t.TempDir()
+			tt.setup(t, dir)
+			info, held := readInstanceLock(dir)
+			if (info != nil) != tt.expInfo || held != tt.expHeld {
+				t.Fatalf("expected info %v and held %v, got %+v and %v", tt.expInfo, tt.expHeld, info, held)
+			}
+			if info != nil && tt.expHeld && info.PID != os.Getpid() {
+				t.Fatalf("expected pid %d, got %d", os.Getpid(), info.PID)
+			}
+		})
+	}
+}
+
+func TestLockInstance(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	webOpts := webFlags{PluginDir: dir}
+	l, err := lockInstance(webOpts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = lockInstance(webOpts); err == nil || !strings.Contains(err.Error(), "is already running") {
+		t.Fatalf("expected the running server to be described, got %v", err)
+	}
+
+	l.Release()
+	l, err = lockInstance(webOpts)
+	if err != nil {
+		t.Fatalf("expected the released lock to be taken, got %v", err)
+	}
+	l.Release()
+}
+
+func writeLockFile(t *testing.T, dir, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, lockFilename), []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,43 @@
+//go:build unix
+
+package web
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"strconv"
+	"syscall"
+)
+
+func lockFile(f *os.File) error {
+	err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB)
+	if errors.Is(err, syscall.EWOULDBLOCK) {
+		return errInstanceLocked
+	}
+	return err
+}
+
+// passLock shares the lock with the child process, the lock is held until both processes release it.
+func passLock(cmd *exec.Cmd, l *instanceLock) {
+	cmd.ExtraFiles = append(cmd.ExtraFiles, l.f)
+	// The extra files follow stdin, stdout and stderr.
+	cmd.Env = append(cmd.Env, lockFdEnvVar.EnvString(strconv.Itoa(2+len(cmd.ExtraFiles))))
+}
+
+// shareLock shares the lock with the child process, the parent keeps holding it while the child runs.
+func shareLock(cmd *exec.Cmd, l *instanceLock) {
+	passLock(cmd, l)
+}
+
+// inheritedLock returns the lock passed by the parent process.
+func inheritedLock() *instanceLock {
+	fd, err := strconv.Atoi(lockFdEnvVar.Get())
+	if err != nil || fd < 3 {
+		return nil
+	}
+	_ = os.Unsetenv(lockFdEnvVar.String())
+	// Don't leak the lock to the actions and other processes started by the server.
+	syscall.CloseOnExec(fd)
+	return &instanceLock{f: os.NewFile(uintptr(fd), lockFilename)}
+}
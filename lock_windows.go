@@ -0,0 +1,49 @@
+//go:build windows
+
+package web
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+
+	"golang.org/x/sys/windows"
+)
+
+func lockFile(f *os.File) error {
+	// Lock a byte far beyond the content, the locked range can't be read by other processes.
+	ol := &windows.Overlapped{Offset: 0, OffsetHigh: 0x7fffffff}
+	err := windows.LockFileEx(windows.Handle(f.Fd()), windows.LOCKFILE_EXCLUSIVE_LOCK|windows.LOCKFILE_FAIL_IMMEDIATELY, 0, 1, 0, ol)
+	if errors.Is(err, windows.ERROR_LOCK_VIOLATION) {
+		return errInstanceLocked
+	}
+	return err
+}
+
+// passLock releases the lock for the child process to take it. The locks on windows belong to the process
+// and are released when it exits even if the child inherits the handle.
+// The lock is best-effort on windows: another server may take it before the child starts,
+// the child fails to start then and the parent reports it when the process exits.
+func passLock(_ *exec.Cmd, l *instanceLock) {
+	l.Release()
+}
+
+// shareLock lets the child process write the lock information while the parent keeps holding the lock.
+// The handle can't be shared on windows, the path of the lock file is passed instead.
+func shareLock(cmd *exec.Cmd, l *instanceLock) {
+	cmd.Env = append(cmd.Env, lockFdEnvVar.EnvString(l.f.Name()))
+}
+
+// inheritedLock opens the lock file held by the parent process to update the lock information.
+func inheritedLock() *instanceLock {
+	path := lockFdEnvVar.Get()
+	if path == "" {
+		return nil
+	}
+	_ = os.Unsetenv(lockFdEnvVar.String())
+	f, err := os.OpenFile(path, os.O_RDWR, 0) //nolint G304 // Path is from the parent process.
+	if err != nil {
+		return nil
+	}
+	return &instanceLock{f: f}
+}
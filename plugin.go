@@ -3,10 +3,15 @@ package web
 
 import (
 	"context"
+	"crypto/tls"
 	_ "embed"
 	"fmt"
+	"net/netip"
+	"net/url"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/launchrctl/launchr"
 	"github.com/launchrctl/launchr/pkg/action"
@@ -17,7 +22,15 @@ import (
 const (
 	pluginName = "web"
 	stopArg    = "stop"
+	statusArg  = "status"
+	restartArg = "restart"
+	logsArg    = "logs"
+	listArg    = "list"
 	pidFile    = "web.pid"
+	dataDir    = "web-data"
+	tokenFile  = "auth-token"
+	// defaultTokenName is a user name of the generated token.
+	defaultTokenName = "default"
 
 	// APIPrefix is a default api prefix on the server.
 	APIPrefix = "/api"
@@ -56,10 +69,30 @@ type webFlags struct {
 	action.WithLogger
 	action.WithTerm
 
+	Name              string
+	Host              string
+	Socket            string
 	Port              int
 	IsPortSet         bool
+	BasePath          string
+	PublicURL         string
+	TrustedProxies    []netip.Prefix
 	ProxyClient       string
 	PluginDir         string
+	DataDir           string
+	AuthTokens        []server.AuthToken
+	LoginTokenFile    string // File of the generated token, the browser logs in with a one-time code.
+	Access            server.AccessConfig
+	Audit             server.AuditOptions
+	Tracing           server.TracingOptions
+	Shutdown          server.ShutdownOptions
+	IdleTimeout       time.Duration
+	Supervise         bool
+	NoBrowser         bool
+	Browser           string // Command template opening the browser.
+	OpenPath          string // Page of the UI to open.
+	TLSCertFile       string
+	TLSKeyFile        string
 	FrontendCustomize server.FrontendCustomize
 	DefaultUISchema   []byte
 }
@@ -68,29 +101,96 @@ type webFlags struct {
 func (p *Plugin) DiscoverActions(_ context.Context) ([]*action.Action, error) {
 	a := action.NewFromYAML("web", actionYaml)
 	a.SetRuntime(action.NewFnRuntime(func(ctx context.Context, a *action.Action) error {
-		pluginTmpDir := p.cfg.Path(pluginName)
-		webPidFile := filepath.Join(pluginTmpDir, pidFile)
 		input := a.Input()
+		name := input.Opt("name").(string)
+		if err := validateInstanceName(name); err != nil {
+			return err
+		}
+		pluginTmpDir := p.instanceDir(name)
+		webPidFile := filepath.Join(pluginTmpDir, pidFile)
 		webRunFlags := webFlags{
+			Name:        name,
 			PluginDir:   pluginTmpDir,
 			Port:        input.Opt("port").(int),
 			IsPortSet:   input.IsOptChanged("port"),
 			ProxyClient: input.Opt("proxy-client").(string),
 			FrontendCustomize: server.FrontendCustomize{
-				Variables:       make(map[string]any),
-				ExcludedActions: make(map[string]bool),
+				Variables: make(map[string]any),
 			},
 			DefaultUISchema: defaultUISchema,
 		}
 
-		// Retrieve a list of excluded actions from config.
-		var excludedActions []string
-		err := p.cfg.Get("web.excluded_actions", &excludedActions)
+		// Persistent data must outlive the plugin temporary directory.
+		err := p.cfg.Get("web.data_dir", &webRunFlags.DataDir)
+		if err != nil {
+			return err
+		}
+		if webRunFlags.DataDir == "" {
+			webRunFlags.DataDir = p.cfg.Path(dataDir)
+		}
+		webRunFlags.DataDir = launchr.MustAbs(webRunFlags.DataDir)
+		// Instances don't share the run history, logs and other data.
+		if name != defaultInstance {
+			webRunFlags.DataDir = filepath.Join(webRunFlags.DataDir, instancesDir, name)
+		}
+
+		err = p.readAuthConfig(&webRunFlags)
+		if err != nil {
+			return err
+		}
+
+		err = p.cfg.Get("web.access", &webRunFlags.Access)
+		if err != nil {
+			return err
+		}
+
+		var audit auditConfig
+		err = p.cfg.Get("web.audit", &audit)
+		if err != nil {
+			return err
+		}
+		webRunFlags.Audit = server.AuditOptions{
+			DirPath:    audit.Dir,
+			MaxSize:    audit.MaxSize << 20,
+			MaxBackups: audit.MaxBackups,
+		}
+		if webRunFlags.Audit.DirPath == "" {
+			webRunFlags.Audit.DirPath = filepath.Join(webRunFlags.DataDir, "audit")
+		}
+		webRunFlags.Audit.DirPath = launchr.MustAbs(webRunFlags.Audit.DirPath)
+
+		var tracing tracingConfig
+		err = p.cfg.Get("web.tracing", &tracing)
+		if err != nil {
+			return err
+		}
+		webRunFlags.Tracing = server.TracingOptions{
+			Exporter: tracing.Exporter,
+			Endpoint: tracing.Endpoint,
+			FilePath: tracing.File,
+		}
+		if tracing.Exporter == server.TracingExporterFile && tracing.File == "" {
+			webRunFlags.Tracing.FilePath = filepath.Join(webRunFlags.DataDir, "traces.jsonl")
+		}
+
+		var shutdown shutdownConfig
+		err = p.cfg.Get("web.shutdown", &shutdown)
+		if err != nil {
+			return err
+		}
+		webRunFlags.Shutdown, err = shutdown.options()
+		if err != nil {
+			return err
+		}
+
+		// Retrieve patterns of excluded and included actions from config.
+		err = p.cfg.Get("web.excluded_actions", &webRunFlags.FrontendCustomize.ExcludedActions)
 		if err != nil {
 			return err
 		}
-		for _, ea := range excludedActions {
-			webRunFlags.FrontendCustomize.ExcludedActions[ea] = true
+		err = p.cfg.Get("web.included_actions", &webRunFlags.FrontendCustomize.IncludedActions)
+		if err != nil {
+			return err
 		}
 
 		var variables map[string]any
@@ -102,6 +202,35 @@ func (p *Plugin) DiscoverActions(_ context.Context) ([]*action.Action, error) {
 			webRunFlags.FrontendCustomize.Variables = variables
 		}
 
+		if idle := input.Opt("idle-timeout").(string); idle != "" {
+			webRunFlags.IdleTimeout, err = time.ParseDuration(idle)
+			if err != nil || webRunFlags.IdleTimeout < time.Second {
+				return fmt.Errorf("--idle-timeout must be a duration of at least 1s, e.g. \"30m\"")
+			}
+		}
+
+		webRunFlags.NoBrowser = input.Opt("no-browser").(bool)
+		err = p.cfg.Get("web.browser", &webRunFlags.Browser)
+		if err != nil {
+			return err
+		}
+		webRunFlags.OpenPath = openPath(input.Opt("open").(string))
+
+		err = setListenFlags(&webRunFlags, input.Opt("host").(string))
+		if err != nil {
+			return err
+		}
+
+		err = setProxyFlags(&webRunFlags, input)
+		if err != nil {
+			return err
+		}
+
+		err = setTLSFlags(&webRunFlags, input)
+		if err != nil {
+			return err
+		}
+
 		// Set action logger. Fallback to default launchr logger.
 		log := launchr.Log()
 		if rt, ok := a.Runtime().(action.RuntimeLoggerAware); ok {
@@ -116,6 +245,10 @@ func (p *Plugin) DiscoverActions(_ context.Context) ([]*action.Action, error) {
 		webRunFlags.SetTerm(term)
 
 		foreground := input.Opt("foreground").(bool)
+		webRunFlags.Supervise = input.Opt("supervise").(bool)
+		if foreground && webRunFlags.Supervise {
+			return fmt.Errorf("--supervise can't be used with --foreground")
+		}
 		// Override client assets.
 		clientAssets := input.Opt("ui-assets").(string)
 		if clientAssets != "" {
@@ -142,23 +275,198 @@ func (p *Plugin) DiscoverActions(_ context.Context) ([]*action.Action, error) {
 		op := input.Arg("op")
 		switch op {
 		case stopArg:
+			if input.Opt("all").(bool) {
+				return p.stopAllWeb(webRunFlags)
+			}
 			return stopWeb(webPidFile, webRunFlags)
-		}
-
-		url, err := getExistingWeb(webPidFile, webRunFlags.PluginDir)
-		if err != nil {
-			launchr.Log().Debug("error on getting server run info", "error", err)
-		}
-
-		if url != "" {
-			return fmt.Errorf("another web UI is already running at %s\nPlease stop the existing server before starting a new one", url)
+		case listArg:
+			return p.listWeb(webRunFlags, input.Opt("json").(bool))
+		case statusArg:
+			return statusWeb(webPidFile, webRunFlags, input.Opt("json").(bool))
+		case logsArg:
+			return logsWeb(ctx, webRunFlags, input.Opt("follow").(bool))
+		case restartArg:
+			// The background process is started with the same arguments, it only starts the server.
+			if !isBackGroundEnv() {
+				args, err := stopForRestart(webPidFile, webRunFlags, input)
+				if err != nil {
+					return err
+				}
+				if !foreground {
+					return p.runBackgroundWeb(ctx, webRunFlags, webPidFile, args)
+				}
+			}
 		}
 
 		if foreground {
 			return p.runWeb(ctx, webRunFlags)
 		}
 
-		return p.runBackgroundWeb(ctx, webRunFlags, webPidFile)
+		return p.runBackgroundWeb(ctx, webRunFlags, webPidFile, os.Args[1:])
 	}))
 	return []*action.Action{a}, nil
 }
+
+// auditConfig is a configuration of the audit log.
+type auditConfig struct {
+	Dir        string `yaml:"dir"`
+	MaxSize    int64  `yaml:"max_size"` // In megabytes.
+	MaxBackups int    `yaml:"max_backups"`
+}
+
+// tracingConfig is a configuration of OpenTelemetry tracing.
+type tracingConfig struct {
+	Exporter string `yaml:"exporter"`
+	Endpoint string `yaml:"endpoint"`
+	File     string `yaml:"file"`
+}
+
+// shutdownConfig is a configuration of the running actions on shutdown.
+type shutdownConfig struct {
+	Policy  string `yaml:"policy"`
+	Timeout string `yaml:"timeout"`
+}
+
+func (c shutdownConfig) options() (server.ShutdownOptions, error) {
+	opts := server.ShutdownOptions{Policy: c.Policy}
+	if c.Timeout != "" {
+		d, err := time.ParseDuration(c.Timeout)
+		if err != nil || d <= 0 {
+			return opts, fmt.Errorf("web.shutdown.timeout: invalid duration %q", c.Timeout)
+		}
+		opts.Timeout = d
+	}
+	if err := opts.Validate(); err != nil {
+		return opts, fmt.Errorf("web.shutdown.policy: %w", err)
+	}
+	return opts, nil
+}
+
+// authConfig is a configuration of the web access.
+type authConfig struct {
+	Enabled bool               `yaml:"enabled"`
+	Tokens  []server.AuthToken `yaml:"tokens"`
+}
+
+// readAuthConfig sets access tokens from config.
+// If authentication is enabled without tokens, a token is generated once and stored in the data dir.
+func (p *Plugin) readAuthConfig(flags *webFlags) error {
+	var cfg authConfig
+	err := p.cfg.Get("web.auth", &cfg)
+	if err != nil {
+		return err
+	}
+	if !cfg.Enabled {
+		return nil
+	}
+
+	for i, t := range cfg.Tokens {
+		if t.Token == "" {
+			return fmt.Errorf("web.auth.tokens: token of %q is empty", t.Name)
+		}
+		if t.Name == "" {
+			cfg.Tokens[i].Name = fmt.Sprintf("token-%d", i+1)
+		}
+	}
+	if len(cfg.Tokens) > 0 {
+		flags.AuthTokens = cfg.Tokens
+		return nil
+	}
+
+	token, err := loadOrCreateToken(filepath.Join(flags.DataDir, tokenFile))
+	if err != nil {
+		return err
+	}
+	flags.AuthTokens = []server.AuthToken{{Name: defaultTokenName, Token: token}}
+	flags.LoginTokenFile = filepath.Join(flags.DataDir, tokenFile)
+	return nil
+}
+
+// openPath returns the path of the UI page of the action or "wizard:<id>" wizard.
+func openPath(id string) string {
+	if id == "" {
+		return ""
+	}
+	if wizard, ok := strings.CutPrefix(id, "wizard:"); ok {
+		return "wizard/" + url.PathEscape(wizard) + "/show"
+	}
+	return "actions/" + url.PathEscape(id) + "/show"
+}
+
+// setListenFlags sets the address to listen on.
+// The host is an IP address or a name, IPv6 addresses may be in brackets, "unix://" prefix sets a socket path.
+func setListenFlags(flags *webFlags, host string) error {
+	if socket, ok := strings.CutPrefix(host, "unix://"); ok {
+		if socket == "" {
+			return fmt.Errorf("socket path is empty in --host %q", host)
+		}
+		if flags.IsPortSet {
+			return fmt.Errorf("--port can't be used with a unix socket")
+		}
+		flags.Socket = launchr.MustAbs(socket)
+		return nil
+	}
+	flags.Host = strings.TrimSuffix(strings.TrimPrefix(host, "["), "]")
+	return nil
+}
+
+// setProxyFlags sets the base path, the public url and the trusted proxies of the server behind a reverse proxy.
+func setProxyFlags(flags *webFlags, input *action.Input) error {
+	trusted, err := server.ParseTrustedProxies(strings.Split(input.Opt("trusted-proxies").(string), ","))
+	if err != nil {
+		return fmt.Errorf("--trusted-proxies: %w", err)
+	}
+	flags.TrustedProxies = trusted
+
+	basePath := input.Opt("base-path").(string)
+	publicURL := input.Opt("public-url").(string)
+	if publicURL != "" {
+		u, err := url.Parse(publicURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("--public-url must be an absolute http(s) url")
+		}
+		// The proxy usually passes the path as is.
+		if !input.IsOptChanged("base-path") {
+			basePath = u.Path
+		}
+		flags.PublicURL = publicURL
+	}
+
+	basePath = strings.Trim(basePath, "/")
+	if basePath != "" {
+		flags.BasePath = "/" + basePath
+	}
+	return nil
+}
+
+// setTLSFlags sets the certificate files from the options.
+// A self-signed certificate is generated once and reused on the next starts.
+func setTLSFlags(flags *webFlags, input *action.Input) error {
+	certFile := input.Opt("tls-cert").(string)
+	keyFile := input.Opt("tls-key").(string)
+	selfSigned := input.Opt("tls-self-signed").(bool)
+
+	switch {
+	case selfSigned && (certFile != "" || keyFile != ""):
+		return fmt.Errorf("--tls-self-signed can't be used with --tls-cert and --tls-key")
+	case selfSigned:
+		var err error
+		certFile, keyFile, err = ensureSelfSignedCert(filepath.Join(flags.DataDir, "tls"), flags.Host)
+		if err != nil {
+			return err
+		}
+	case certFile == "" && keyFile == "":
+		return nil
+	case certFile == "" || keyFile == "":
+		return fmt.Errorf("both --tls-cert and --tls-key must be set")
+	default:
+		// Fail early, the server may be started in the background.
+		if _, err := tls.LoadX509KeyPair(certFile, keyFile); err != nil {
+			return fmt.Errorf("invalid TLS certificate: %w", err)
+		}
+	}
+
+	flags.TLSCertFile = launchr.MustAbs(certFile)
+	flags.TLSKeyFile = launchr.MustAbs(keyFile)
+	return nil
+}
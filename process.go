@@ -5,8 +5,11 @@ import (
 	"os"
 	"path/filepath"
 	"strconv"
+	"time"
 )
 
+// pidFileInfo returns the pid of the background server and whether it's running.
+// The process is checked only while the instance lock is held, the pid may be reused after the server is gone.
 func pidFileInfo(path string) (pid int, active bool) {
 	var err error
 	if _, err = os.Stat(path); err != nil {
@@ -16,6 +19,9 @@ func pidFileInfo(path string) (pid int, active bool) {
 	if err != nil {
 		return 0, false
 	}
+	if _, held := readInstanceLock(filepath.Dir(path)); !held {
+		return pid, false
+	}
 	return pid, isProcessRunning(pid)
 }
 
@@ -58,3 +64,15 @@ func interruptProcess(pid int) error {
 
 	return nil
 }
+
+// waitProcessExit waits until the process exits or the timeout passes.
+func waitProcessExit(pid int, timeout time.Duration) bool {
+	deadline := time.Now().Add(timeout)
+	for isProcessRunning(pid) {
+		if time.Now().After(deadline) {
+			return false
+		}
+		time.Sleep(200 * time.Millisecond)
+	}
+	return true
+}
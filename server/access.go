@@ -0,0 +1,195 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// AccessPermission is an operation on an action.
+type AccessPermission string
+
+// Permissions checked by the access policy.
+const (
+	PermissionView   AccessPermission = "view"
+	PermissionRun    AccessPermission = "run"
+	PermissionCancel AccessPermission = "cancel"
+)
+
+// AccessRule allows and denies actions by patterns over action IDs, see [compileActionPatterns].
+type AccessRule struct {
+	Allow []string `yaml:"allow"`
+	Deny  []string `yaml:"deny"`
+}
+
+// AccessRole grants permissions to the listed users.
+// Users are names of the access tokens, "*" matches everyone.
+// Admins may read the audit log.
+type AccessRole struct {
+	Users  []string   `yaml:"users"`
+	Admin  bool       `yaml:"admin"`
+	View   AccessRule `yaml:"view"`
+	Run    AccessRule `yaml:"run"`
+	Cancel AccessRule `yaml:"cancel"`
+}
+
+// AccessConfig maps roles by name. Everything is allowed if no roles are defined.
+type AccessConfig struct {
+	Roles map[string]AccessRole `yaml:"roles"`
+}
+
+// actionPattern matches action IDs by a glob or a regular expression.
+type actionPattern struct {
+	glob string
+	re   *regexp.Regexp
+}
+
+// actionPatterns matches an action ID if any of the patterns matches.
+type actionPatterns []actionPattern
+
+// compileActionPatterns parses patterns over action IDs.
+// A pattern enclosed in slashes is a regular expression, e.g. "/^deploy:(staging|qa)$/",
+// other patterns are globs, e.g. "interaction.*:create-*".
+func compileActionPatterns(patterns []string) (actionPatterns, error) {
+	result := make(actionPatterns, 0, len(patterns))
+	for _, p := range patterns {
+		if len(p) > 2 && strings.HasPrefix(p, "/") && strings.HasSuffix(p, "/") {
+			re, err := regexp.Compile(p[1 : len(p)-1])
+			if err != nil {
+				return nil, fmt.Errorf("invalid pattern %q: %w", p, err)
+			}
+			result = append(result, actionPattern{re: re})
+			continue
+		}
+		if _, err := path.Match(p, ""); err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %w", p, err)
+		}
+		result = append(result, actionPattern{glob: p})
+	}
+	return result, nil
+}
+
+// Match checks if the action ID matches any pattern.
+func (ps actionPatterns) Match(id string) bool {
+	for _, p := range ps {
+		if p.re != nil {
+			if p.re.MatchString(id) {
+				return true
+			}
+			continue
+		}
+		if ok, _ := path.Match(p.glob, id); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// accessRule is a compiled [AccessRule].
+type accessRule struct {
+	allow actionPatterns
+	deny  actionPatterns
+}
+
+// accessRole is a compiled [AccessRole].
+type accessRole struct {
+	users map[string]bool
+	admin bool
+	rules map[AccessPermission]accessRule
+}
+
+// accessPolicy decides if a user may do an operation on an action.
+// Excluded actions are denied for everyone. If included actions are set, only they are available.
+type accessPolicy struct {
+	excluded actionPatterns
+	included actionPatterns
+	roles    []accessRole
+}
+
+func newAccessPolicy(cfg AccessConfig, excluded, included []string) (*accessPolicy, error) {
+	var err error
+	p := &accessPolicy{}
+	if p.excluded, err = compileActionPatterns(excluded); err != nil {
+		return nil, fmt.Errorf("excluded actions: %w", err)
+	}
+	if p.included, err = compileActionPatterns(included); err != nil {
+		return nil, fmt.Errorf("included actions: %w", err)
+	}
+
+	for name, role := range cfg.Roles {
+		r := accessRole{
+			users: make(map[string]bool, len(role.Users)),
+			admin: role.Admin,
+			rules: make(map[AccessPermission]accessRule, 3),
+		}
+		for _, u := range role.Users {
+			r.users[u] = true
+		}
+		perms := map[AccessPermission]AccessRule{
+			PermissionView:   role.View,
+			PermissionRun:    role.Run,
+			PermissionCancel: role.Cancel,
+		}
+		for perm, rule := range perms {
+			var compiled accessRule
+			if compiled.allow, err = compileActionPatterns(rule.Allow); err != nil {
+				return nil, fmt.Errorf("access role %q: %w", name, err)
+			}
+			if compiled.deny, err = compileActionPatterns(rule.Deny); err != nil {
+				return nil, fmt.Errorf("access role %q: %w", name, err)
+			}
+			r.rules[perm] = compiled
+		}
+		p.roles = append(p.roles, r)
+	}
+	return p, nil
+}
+
+// Allowed checks the permission of the user for the action.
+// The action is allowed if any role of the user allows it and none denies it.
+func (p *accessPolicy) Allowed(user string, perm AccessPermission, actionID string) bool {
+	if p.excluded.Match(actionID) {
+		return false
+	}
+	if len(p.included) > 0 && !p.included.Match(actionID) {
+		return false
+	}
+	if len(p.roles) == 0 {
+		return true
+	}
+
+	allowed := false
+	for _, role := range p.roles {
+		if !role.users["*"] && !role.users[user] {
+			continue
+		}
+		rule := role.rules[perm]
+		if rule.deny.Match(actionID) {
+			return false
+		}
+		if rule.allow.Match(actionID) {
+			allowed = true
+		}
+	}
+	return allowed
+}
+
+// IsAdmin checks if the user has an admin role. Everyone is an admin if no roles are defined.
+func (p *accessPolicy) IsAdmin(user string) bool {
+	if len(p.roles) == 0 {
+		return true
+	}
+	for _, role := range p.roles {
+		if role.admin && (role.users["*"] || role.users[user]) {
+			return true
+		}
+	}
+	return false
+}
+
+// can checks the permission of the request user for the action.
+func (l *launchrServer) can(r *http.Request, perm AccessPermission, actionID string) bool {
+	return l.access.Allowed(identityFromContext(r.Context()), perm, actionID)
+}
@@ -0,0 +1,166 @@
+package server
+
+import (
+	"testing"
+)
+
+func TestActionPatterns(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		patterns []string
+		id       string
+		exp      bool
+		expErr   bool
+	}{
+		{"exact id", []string{"deploy:staging"}, "deploy:staging", true, false},
+		{"glob", []string{"deploy:*"}, "deploy:staging", true, false},
+		{"glob not matching", []string{"deploy:*"}, "build:image", false, false},
+		{"glob in the namespace", []string{"interaction.*:create-*"}, "interaction.user:create-post", true, false},
+		{"glob character class", []string{"deploy:[sq]*"}, "deploy:qa", true, false},
+		{"any of the patterns", []string{"build:*", "deploy:*"}, "deploy:qa", true, false},
+		{"regexp", []string{"/^deploy:(staging|qa)$/"}, "deploy:qa", true, false},
+		{"regexp not matching", []string{"/^deploy:(staging|qa)$/"}, "deploy:production", false, false},
+		{"regexp without anchors", []string{"/prod/"}, "deploy:production", true, false},
+		{"single slash is a glob", []string{"/"}, "/", true, false},
+		{"no patterns", nil, "deploy:qa", false, false},
+		{"invalid glob", []string{"deploy:["}, "", false, true},
+		{"invalid regexp", []string{"/deploy:(/"}, "", false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ps, err := compileActionPatterns(tt.patterns)
+			if (err != nil) != tt.expErr {
+				t.Fatalf("expected error %v, got %v", tt.expErr, err)
+			}
+			if tt.expErr {
+				return
+			}
+			if got := ps.Match(tt.id); got != tt.exp {
+				t.Fatalf("expected %v, got %v", tt.exp, got)
+			}
+		})
+	}
+}
+
+func TestAccessPolicyIncludedExcluded(t *testing.T) {
+	t.Parallel()
+
+	roles := AccessConfig{Roles: map[string]AccessRole{
+		"viewer": {Users: []string{"*"}, View: AccessRule{Allow: []string{"*"}}},
+	}}
+	tests := []struct {
+		name     string
+		cfg      AccessConfig
+		excluded []string
+		included []string
+		actionID string
+		exp      bool
+	}{
+		{"nothing set", AccessConfig{}, nil, nil, "deploy:qa", true},
+		{"excluded", AccessConfig{}, []string{"deploy:*"}, nil, "deploy:qa", false},
+		{"not excluded", AccessConfig{}, []string{"deploy:*"}, nil, "build:image", true},
+		{"included", AccessConfig{}, nil, []string{"deploy:*"}, "deploy:qa", true},
+		{"not included", AccessConfig{}, nil, []string{"deploy:*"}, "build:image", false},
+		{"excluded wins over included", AccessConfig{}, []string{"deploy:production"}, []string{"deploy:*"}, "deploy:production", false},
+		{"excluded wins over roles", roles, []string{"/^deploy:/"}, nil, "deploy:qa", false},
+		{"not included despite the roles", roles, nil, []string{"build:*"}, "deploy:qa", false},
+		{"included and allowed by the roles", roles, nil, []string{"deploy:*"}, "deploy:qa", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			p, err := newAccessPolicy(tt.cfg, tt.excluded, tt.included)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := p.Allowed("eve", PermissionView, tt.actionID); got != tt.exp {
+				t.Fatalf("expected %v, got %v", tt.exp, got)
+			}
+		})
+	}
+}
+
+func TestAccessPolicyRoles(t *testing.T) {
+	t.Parallel()
+
+	cfg := AccessConfig{Roles: map[string]AccessRole{
+		"viewer": {
+			Users: []string{"*"},
+			View:  AccessRule{Allow: []string{"*"}},
+		},
+		"operator": {
+			Users:  []string{"alice", "bob"},
+			Run:    AccessRule{Allow: []string{"deploy:*"}, Deny: []string{"deploy:production"}},
+			Cancel: AccessRule{Allow: []string{"deploy:*"}},
+		},
+		"releaser": {
+			Users: []string{"bob"},
+			Run:   AccessRule{Allow: []string{"deploy:production"}},
+		},
+		"admin": {
+			Users: []string{"alice"},
+			Admin: true,
+		},
+	}}
+	p, err := newAccessPolicy(cfg, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		user     string
+		perm     AccessPermission
+		actionID string
+		exp      bool
+	}{
+		{"everyone views", "eve", PermissionView, "deploy:staging", true},
+		{"anonymous views", "", PermissionView, "deploy:staging", true},
+		{"not granted permission", "eve", PermissionRun, "deploy:staging", false},
+		{"allowed by the role", "alice", PermissionRun, "deploy:staging", true},
+		{"not matching the role", "alice", PermissionRun, "build:image", false},
+		{"denied by the role", "alice", PermissionRun, "deploy:production", false},
+		{"deny wins over allow of another role", "bob", PermissionRun, "deploy:production", false},
+		{"permissions are separate", "alice", PermissionCancel, "deploy:production", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := p.Allowed(tt.user, tt.perm, tt.actionID); got != tt.exp {
+				t.Fatalf("expected %v, got %v", tt.exp, got)
+			}
+		})
+	}
+}
+
+func TestAccessPolicyIsAdmin(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		cfg  AccessConfig
+		user string
+		exp  bool
+	}{
+		{"no roles", AccessConfig{}, "eve", true},
+		{"admin", AccessConfig{Roles: map[string]AccessRole{"admin": {Users: []string{"alice"}, Admin: true}}}, "alice", true},
+		{"not admin", AccessConfig{Roles: map[string]AccessRole{"admin": {Users: []string{"alice"}, Admin: true}}}, "eve", false},
+		{"everyone is admin", AccessConfig{Roles: map[string]AccessRole{"admin": {Users: []string{"*"}, Admin: true}}}, "eve", true},
+		{"role without admin", AccessConfig{Roles: map[string]AccessRole{"viewer": {Users: []string{"*"}}}}, "eve", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			p, err := newAccessPolicy(tt.cfg, nil, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := p.IsAdmin(tt.user); got != tt.exp {
+				t.Fatalf("expected %v, got %v", tt.exp, got)
+			}
+		})
+	}
+}
@@ -2,8 +2,11 @@ package server
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"net/url"
 	"os"
@@ -11,17 +14,18 @@ import (
 	"sort"
 	"strconv"
 	"strings"
-	"sync"
 	"time"
 
 	"github.com/knadh/koanf"
 	yamlparser "github.com/knadh/koanf/parsers/yaml"
 	"github.com/knadh/koanf/providers/file"
 	"github.com/knadh/koanf/providers/rawbytes"
+	"go.opentelemetry.io/otel/trace"
 	"gopkg.in/yaml.v3"
 
 	"github.com/launchrctl/launchr"
 	"github.com/launchrctl/launchr/pkg/action"
+	"github.com/launchrctl/launchr/pkg/jsonschema"
 )
 
 // customizationPlatformNameKey used to set the layout-flow root name
@@ -35,19 +39,30 @@ type launchrServer struct {
 	stateMngr    *StateManager
 	cfg          launchr.Config
 	ctx          context.Context
-	baseURL      string
+	public       *publicURL
 	apiPrefix    string
-	wsMutex      sync.Mutex
 	customize    FrontendCustomize
 	uiSchemaBase []byte
 	logsDirPath  string
+	history      *runHistory
+	events       *eventHub
+	devOrigin    string
+	access       *accessPolicy
+	audit        *auditLog
+	metrics      *serverMetrics
+	tracing      *tracing
+	startedAt    time.Time
+	runs         runTracker
 	app          launchr.App
 }
 
 // FrontendCustomize stores variables to customize web appearance.
 type FrontendCustomize struct {
-	Variables       map[string]any
-	ExcludedActions map[string]bool
+	Variables map[string]any
+	// ExcludedActions are patterns of actions hidden in the web.
+	ExcludedActions []string
+	// IncludedActions are patterns of the only actions available in the web if set.
+	IncludedActions []string
 }
 
 func (l *launchrServer) GetCustomisationConfig(w http.ResponseWriter, _ *http.Request) {
@@ -62,9 +77,9 @@ func (l *launchrServer) GetCustomisationConfig(w http.ResponseWriter, _ *http.Re
 	_ = json.NewEncoder(w).Encode(customisation)
 }
 
-func (l *launchrServer) GetOneRunningActionByID(w http.ResponseWriter, _ *http.Request, id ActionId, runID ActionRunInfoId) {
+func (l *launchrServer) GetOneRunningActionByID(w http.ResponseWriter, r *http.Request, id ActionId, runID ActionRunInfoId) {
 	ri, ok := l.actionMngr.RunInfoByID(runID)
-	if !ok {
+	if !ok || !l.can(r, PermissionView, ri.Action.ID) {
 		sendError(w, http.StatusNotFound, fmt.Sprintf("action run info with id %q is not found", id))
 		return
 	}
@@ -75,12 +90,19 @@ func (l *launchrServer) GetOneRunningActionByID(w http.ResponseWriter, _ *http.R
 	})
 }
 
-func (l *launchrServer) CancelRunningAction(w http.ResponseWriter, _ *http.Request, id ActionId, runID ActionRunInfoId) {
+func (l *launchrServer) CancelRunningAction(w http.ResponseWriter, r *http.Request, id ActionId, runID ActionRunInfoId) {
+	audit := auditDetailsFromContext(r.Context())
+	audit.ActionID, audit.RunID = id, runID
 	ri, ok := l.actionMngr.RunInfoByID(runID)
-	if !ok {
+	if !ok || !l.can(r, PermissionView, ri.Action.ID) {
 		sendError(w, http.StatusNotFound, fmt.Sprintf("action run info with id %q is not found", id))
 		return
 	}
+	audit.ActionID = ri.Action.ID
+	if !l.can(r, PermissionCancel, ri.Action.ID) {
+		sendError(w, http.StatusForbidden, fmt.Sprintf("cancelling action %q is not allowed", ri.Action.ID))
+		return
+	}
 
 	if ri.Status != "running" {
 		sendError(w, http.StatusNotFound, fmt.Sprintf("action %q is not running", id))
@@ -100,36 +122,44 @@ func (l *launchrServer) CancelRunningAction(w http.ResponseWriter, _ *http.Reque
 	_ = json.NewEncoder(w).Encode(struct{}{})
 }
 
-func (l *launchrServer) GetRunningActionStreams(w http.ResponseWriter, _ *http.Request, id ActionId, runID ActionRunInfoId, params GetRunningActionStreamsParams) {
+func (l *launchrServer) GetRunningActionStreams(w http.ResponseWriter, r *http.Request, id ActionId, runID ActionRunInfoId, params GetRunningActionStreamsParams) {
 	ri, ok := l.actionMngr.RunInfoByID(runID)
-	if !ok {
+	if !ok || !l.can(r, PermissionView, ri.Action.ID) {
 		sendError(w, http.StatusNotFound, fmt.Sprintf("action run info with id %q is not found", id))
 		return
 	}
+	if params.Type != nil && *params.Type != "" && *params.Type != string(StdOut) && *params.Type != string(StdErr) {
+		sendError(w, http.StatusBadRequest, fmt.Sprintf("unknown stream type %q", *params.Type))
+		return
+	}
 	streams := ri.Action.Input().Streams()
 	fStreams, ok := streams.(fileStreams)
 	if !ok {
 		panic("not supported")
 	}
-	sd, err := fStreams.GetStreamData(params)
+	// Get the record before reading the output, so the output of a finished run is read completely.
+	rec, _ := l.history.Get(runID)
+	sd, err := fStreams.GetStreamData(params, rec.FinishedAt != nil)
 	if err != nil {
 		sendError(w, http.StatusInternalServerError, "Error reading streams")
+		return
 	}
 
 	w.WriteHeader(http.StatusOK)
 	_ = json.NewEncoder(w).Encode(sd)
 }
 
-func (l *launchrServer) basePath() string {
-	return l.baseURL + l.apiPrefix
+// apiURL returns the absolute URL of the API seen by the client.
+func (l *launchrServer) apiURL(r *http.Request) string {
+	return l.public.root(r) + l.apiPrefix
 }
 
-func (l *launchrServer) GetActions(w http.ResponseWriter, _ *http.Request) {
+func (l *launchrServer) GetActions(w http.ResponseWriter, r *http.Request) {
 	actions := l.actionMngr.All()
 	var result = make([]ActionShort, 0, len(actions))
 	for _, a := range actions {
-		// skip excluded actions
-		if _, ok := l.customize.ExcludedActions[a.ID]; ok {
+		// Skip excluded and not permitted actions.
+		if !l.can(r, PermissionView, a.ID) {
 			continue
 		}
 
@@ -148,15 +178,15 @@ func (l *launchrServer) GetActions(w http.ResponseWriter, _ *http.Request) {
 	_ = json.NewEncoder(w).Encode(result)
 }
 
-func (l *launchrServer) GetActionByID(w http.ResponseWriter, _ *http.Request, id string) {
+func (l *launchrServer) GetActionByID(w http.ResponseWriter, r *http.Request, id string) {
 	// @todo return executing actions for a show page.
 	a, ok := l.actionMngr.Get(id)
-	if !ok {
+	if !ok || !l.can(r, PermissionView, id) {
 		sendError(w, http.StatusNotFound, fmt.Sprintf("action with id %q is not found", id))
 		return
 	}
 
-	afull, err := l.apiActionFull(a)
+	afull, err := l.apiActionFull(r, a)
 	if err != nil {
 		sendError(w, http.StatusInternalServerError, fmt.Sprintf("error on building actionFull %q", id))
 		return
@@ -166,14 +196,14 @@ func (l *launchrServer) GetActionByID(w http.ResponseWriter, _ *http.Request, id
 	_ = json.NewEncoder(w).Encode(afull)
 }
 
-func (l *launchrServer) GetActionJSONSchema(w http.ResponseWriter, _ *http.Request, id string) {
+func (l *launchrServer) GetActionJSONSchema(w http.ResponseWriter, r *http.Request, id string) {
 	a, ok := l.actionMngr.Get(id)
-	if !ok {
+	if !ok || !l.can(r, PermissionView, id) {
 		sendError(w, http.StatusNotFound, fmt.Sprintf("action with id %q is not found", id))
 		return
 	}
 
-	afull, err := l.apiActionFull(a)
+	afull, err := l.apiActionFull(r, a)
 	if err != nil {
 		sendError(w, http.StatusInternalServerError, fmt.Sprintf("error on building actionFull %q", id))
 		return
@@ -183,7 +213,11 @@ func (l *launchrServer) GetActionJSONSchema(w http.ResponseWriter, _ *http.Reque
 	_ = json.NewEncoder(w).Encode(afull.JSONSchema)
 }
 
-func (l *launchrServer) GetRunningActionsByID(w http.ResponseWriter, _ *http.Request, id string) {
+func (l *launchrServer) GetRunningActionsByID(w http.ResponseWriter, r *http.Request, id string) {
+	if !l.can(r, PermissionView, id) {
+		sendError(w, http.StatusNotFound, fmt.Sprintf("action with id %q is not found", id))
+		return
+	}
 	runningActions := l.actionMngr.RunInfoByAction(id)
 
 	sort.Slice(runningActions, func(i, j int) bool {
@@ -201,7 +235,53 @@ func (l *launchrServer) GetRunningActionsByID(w http.ResponseWriter, _ *http.Req
 	_ = json.NewEncoder(w).Encode(result)
 }
 
-func (l *launchrServer) GetWizards(w http.ResponseWriter, _ *http.Request) {
+func (l *launchrServer) GetRuns(w http.ResponseWriter, r *http.Request, params GetRunsParams) {
+	filter := runsFilter(params)
+	all := l.history.List()
+	records := make([]ActionRunRecord, 0, len(all))
+	for _, rec := range all {
+		rec = l.actualRunRecord(rec)
+		if filter(rec) && l.can(r, PermissionView, rec.ActionID) {
+			records = append(records, rec)
+		}
+	}
+
+	page, next, err := paginateRuns(records, params)
+	if err != nil {
+		sendError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(ActionRunRecordList{
+		Items:      page,
+		NextCursor: next,
+	})
+}
+
+func (l *launchrServer) GetRunByID(w http.ResponseWriter, r *http.Request, runID ActionRunInfoId) {
+	rec, ok := l.history.Get(runID)
+	if !ok || !l.can(r, PermissionView, rec.ActionID) {
+		sendError(w, http.StatusNotFound, fmt.Sprintf("action run with id %q is not found", runID))
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(l.actualRunRecord(rec))
+}
+
+// actualRunRecord updates status of an unfinished run from the action manager.
+func (l *launchrServer) actualRunRecord(rec ActionRunRecord) ActionRunRecord {
+	if rec.FinishedAt != nil {
+		return rec
+	}
+	if ri, ok := l.actionMngr.RunInfoByID(rec.ID); ok {
+		rec.Status = ActionRunStatus(ri.Status)
+	}
+	return rec
+}
+
+func (l *launchrServer) GetWizards(w http.ResponseWriter, r *http.Request) {
 	var result []WizardShort
 
 	runDir, fileErr := os.Getwd()
@@ -223,13 +303,27 @@ func (l *launchrServer) GetWizards(w http.ResponseWriter, _ *http.Request) {
 			}
 
 			var data struct {
-				UIWizard WizardShort `yaml:"uiWizard"`
+				UIWizard struct {
+					WizardShort `yaml:",inline"`
+					Steps       []struct {
+						Actions []string `yaml:"actions"`
+					} `yaml:"steps"`
+				} `yaml:"uiWizard"`
 			}
 			err = yaml.Unmarshal(content, &data)
 			if err != nil {
 				return err
 			}
 
+			// Hide wizards with actions the user can't see.
+			for _, step := range data.UIWizard.Steps {
+				for _, actionID := range step.Actions {
+					if !l.can(r, PermissionView, actionID) {
+						return nil
+					}
+				}
+			}
+
 			relativePath, err := filepath.Rel(runDir, path)
 			if err != nil {
 				return err
@@ -256,7 +350,7 @@ func (l *launchrServer) GetWizards(w http.ResponseWriter, _ *http.Request) {
 	_ = json.NewEncoder(w).Encode(result)
 }
 
-func (l *launchrServer) GetWizardByID(w http.ResponseWriter, _ *http.Request, id WizardId) {
+func (l *launchrServer) GetWizardByID(w http.ResponseWriter, r *http.Request, id WizardId) {
 	runDir, err := os.Getwd()
 	if err != nil {
 		sendError(w, http.StatusInternalServerError, "Error getting working directory")
@@ -301,6 +395,10 @@ func (l *launchrServer) GetWizardByID(w http.ResponseWriter, _ *http.Request, id
 	for _, step := range data.UIWizard.Steps {
 		var actions []ActionFull
 		for _, actionID := range step.Actions {
+			if !l.can(r, PermissionView, actionID) {
+				sendError(w, http.StatusNotFound, fmt.Sprintf("Wizard with ID %q not found", id))
+				return
+			}
 			a, ok := l.actionMngr.Get(actionID)
 			if !ok {
 				sendError(w, http.StatusInternalServerError, fmt.Sprintf("Step action with ID %q not found", actionID))
@@ -312,7 +410,7 @@ func (l *launchrServer) GetWizardByID(w http.ResponseWriter, _ *http.Request, id
 				return
 			}
 
-			afull, err := l.apiActionFull(a)
+			afull, err := l.apiActionFull(r, a)
 			if err != nil {
 				sendError(w, http.StatusInternalServerError, fmt.Sprintf("Error building ActionFull for %q", actionID))
 				return
@@ -346,12 +444,30 @@ func (l *launchrServer) GetWizardByID(w http.ResponseWriter, _ *http.Request, id
 func (l *launchrServer) RunAction(w http.ResponseWriter, r *http.Request, id string) {
 	// @todo error if action is already running. We need some pool of running processes with its io.
 	var err error
+	audit := auditDetailsFromContext(r.Context())
+	audit.ActionID = id
+	// The run is registered before it's prepared, so the shutdown doesn't miss the runs being started.
+	// The run is released when it finishes or here if it's not started.
+	if !l.runs.start() {
+		sendError(w, http.StatusServiceUnavailable, "Server is shutting down")
+		return
+	}
+	running := false
+	defer func() {
+		if !running {
+			l.runs.done()
+		}
+	}()
+
 	a, ok := l.actionMngr.Get(id)
-	_, excluded := l.customize.ExcludedActions[a.ID]
-	if !ok || excluded {
+	if !ok || !l.can(r, PermissionView, id) {
 		sendError(w, http.StatusNotFound, fmt.Sprintf("action with id %q is not found", id))
 		return
 	}
+	if !l.can(r, PermissionRun, id) {
+		sendError(w, http.StatusForbidden, fmt.Sprintf("running action %q is not allowed", id))
+		return
+	}
 
 	// Parse JSON Schema input.
 	var params ActionRunParams
@@ -359,16 +475,14 @@ func (l *launchrServer) RunAction(w http.ResponseWriter, r *http.Request, id str
 		sendError(w, http.StatusBadRequest, "Invalid format for ActionRunParams")
 		return
 	}
+	inputSchema := l.actionInputSchema(r, a)
+	auditParams := l.safeRunParams(inputSchema, params)
+	audit.Params = &auditParams
 
-	// Generate custom runID.
-	runID := strconv.FormatInt(time.Now().Unix(), 10) + "___" + a.ID
-
-	defer func() {
-		//if err != nil {
-		//	streams.Close()
-		//}
-	}()
+	runID := newRunID(a.ID)
+	audit.RunID = runID
 
+	formParams := params
 	persistentFlags := l.actionMngr.GetPersistentFlags()
 	params = convertUserInput(a, persistentFlags.GetDefinitions(), params)
 
@@ -378,11 +492,100 @@ func (l *launchrServer) RunAction(w http.ResponseWriter, r *http.Request, id str
 
 	// Prepare action for run.
 	// Can we fetch directly json?
-	streams, err := createFileStreams(l.logsDirPath, runID, l.app, quiet)
+	streams, err := createFileStreams(l.logsDirPath, runID, l.app, quiet, func(sd *ActionRunStreamData) {
+		l.events.Publish(runEvent{Type: runEventOutput, ActionID: a.ID, RunID: runID, Output: sd})
+	})
 	if err != nil {
 		sendError(w, http.StatusInternalServerError, "Error preparing streams")
+		return
+	}
+
+	input := l.newActionInput(a, params, streams)
+	err = l.actionMngr.ValidateInput(a, input)
+	if err != nil {
+		l.Log().Warn("Failed to validate input", "error", err)
+		streams.remove()
+		sendValidationError(w, l.explainInputError(r, a, formParams, err))
+		return
+	}
+
+	err = a.SetInput(input)
+	if err != nil {
+		l.Log().Error("Failed to set input", "error", err)
+		streams.remove()
+		sendError(w, http.StatusBadRequest, fmt.Sprintf("Failed to set input: %q", err))
+		return
+	}
+
+	l.actionMngr.Decorate(a)
+	state := l.stateMngr.registerState(runID)
+	span := l.tracing.startRun(r.Context(), a.ID, runID)
+	l.tracing.setRunEnv(a, span)
+	ri, chErr := l.actionMngr.RunBackground(state.context, a, runID)
+	runInfo := ActionRunInfo{
+		ID:     ri.ID,
+		Status: ActionRunStatus(ri.Status),
+	}
+
+	rec := newRunRecord(runInfo, a.ID, l.safeRunParams(inputSchema, params), streams.logs())
+	if err = l.history.Save(rec); err != nil {
+		l.Log().Error("Failed to save run to history", "runID", runID, "error", err)
+	}
+
+	l.metrics.runStarted(a.ID)
+	l.events.Publish(runEvent{Type: runEventCreated, ActionID: a.ID, RunID: runID, Status: runInfo.Status})
+	running = true
+	go l.watchRun(a.ID, runID, runInfo.Status, streams, chErr, span)
+
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(runInfo)
+}
+
+// newRunID generates a unique run id. Several runs of the action may start in the same second.
+// The id starts with the unix time, the client shows it as the start time of the run.
+func newRunID(actionID string) string {
+	suffix := make([]byte, 8)
+	_, _ = rand.Read(suffix)
+	return strconv.FormatInt(time.Now().Unix(), 10) + "-" + hex.EncodeToString(suffix) + "___" + actionID
+}
+
+func (l *launchrServer) ValidateActionInput(w http.ResponseWriter, r *http.Request, id ActionId) {
+	// The validation changes nothing, it's sent on every edit of the form.
+	auditDetailsFromContext(r.Context()).ReadOnly = true
+	a, ok := l.actionMngr.Get(id)
+	if !ok || !l.can(r, PermissionView, id) {
+		sendError(w, http.StatusNotFound, fmt.Sprintf("action with id %q is not found", id))
+		return
 	}
+	// The input is validated before a run, it's available to those who may run the action.
+	if !l.can(r, PermissionRun, id) {
+		sendError(w, http.StatusForbidden, fmt.Sprintf("running action %q is not allowed", id))
+		return
+	}
+
+	var params ActionRunParams
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		sendError(w, http.StatusBadRequest, "Invalid format for ActionRunParams")
+		return
+	}
+
+	formParams := params
+	params = convertUserInput(a, l.actionMngr.GetPersistentFlags().GetDefinitions(), params)
 
+	// The action is not run, the output is not needed.
+	streams := launchr.NewBasicStreams(nil, io.Discard, io.Discard)
+	input := l.newActionInput(a, params, streams)
+	if err := l.actionMngr.ValidateInput(a, input); err != nil {
+		sendValidationError(w, l.explainInputError(r, a, formParams, err))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// newActionInput creates the action input from the converted user input.
+// Runtime flags are set if the action supports them, persistent flags fall back to their current values.
+func (l *launchrServer) newActionInput(a *action.Action, params ActionRunParams, streams launchr.Streams) *action.Input {
 	input := action.NewInput(a, params.Arguments, params.Options, streams)
 
 	// set runtime flags if any.
@@ -393,6 +596,7 @@ func (l *launchrServer) RunAction(w http.ResponseWriter, r *http.Request, id str
 		}
 	}
 	// set persistent flags
+	persistentFlags := l.actionMngr.GetPersistentFlags()
 	for k, v := range persistentFlags.GetAll() {
 		if _, ok := params.Persistent[k]; ok {
 			input.SetFlagInGroup(persistentFlags.GetName(), k, params.Persistent[k])
@@ -401,64 +605,99 @@ func (l *launchrServer) RunAction(w http.ResponseWriter, r *http.Request, id str
 		}
 	}
 
-	err = l.actionMngr.ValidateInput(a, input)
-	if err != nil {
-		// @todo validate must have info about which fields failed.
-		// @todo change to json
-		l.Log().Warn("Failed to validate input", "error", err)
-		sendError(w, http.StatusBadRequest, "The input provided is invalid. Please check your form values and try again.")
-
-		return
-	}
+	return input
+}
 
-	err = a.SetInput(input)
+// explainInputError returns the list of invalid fields for the failed input validation.
+func (l *launchrServer) explainInputError(r *http.Request, a *action.Action, params ActionRunParams, validateErr error) []InputFieldError {
+	afull, err := l.apiActionFull(r, a)
 	if err != nil {
-		l.Log().Error("Failed to set input", "error", err)
-		sendError(w, http.StatusBadRequest, fmt.Sprintf("Failed to set input: %q", err))
-		return
+		l.Log().Error("Failed to build action schema", "action_id", a.ID, "error", err)
+	}
+	var fields []InputFieldError
+	if err == nil {
+		fields, err = inputFieldErrors(afull.JSONSchema, params)
+		if err != nil {
+			l.Log().Error("Failed to explain input validation error", "action_id", a.ID, "error", err)
+		}
 	}
+	if len(fields) == 0 {
+		// The form is valid against the schema, but the action still rejects the input.
+		// The error concerns the whole input, the empty pointer refers to the root.
+		fields = []InputFieldError{{Pointer: "", Keyword: inputErrorKeywordUnknown, Message: validateErr.Error()}}
+	}
+	return fields
+}
 
-	l.actionMngr.Decorate(a)
-	state := l.stateMngr.registerState(runID)
-	ri, chErr := l.actionMngr.RunBackground(state.context, a, runID)
+// watchRun publishes run status changes until the run is finished.
+func (l *launchrServer) watchRun(actionID, runID string, status ActionRunStatus, streams *webCli, chErr chan error, span trace.Span) {
+	startedAt := time.Now()
+	defer l.runs.done()
+	// The action manager doesn't notify when the run starts, check the switch from created with a backoff.
+	// The final status is published when the run ends.
+	startCheckDelay := runStartCheckMin
+	startCheck := time.After(startCheckDelay)
+
+	for {
+		select {
+		case err := <-chErr:
+			if err != nil {
+				l.Log().Error("Action execution failed", "runID", runID, "error", err)
+				// save error to error file
+				if _, writeErr := streams.Err().Write([]byte(err.Error())); writeErr != nil {
+					l.Log().Error("Failed to write error to stream", "error", writeErr)
+				}
 
-	go func() {
-		err := <-chErr
-		if err != nil {
-			l.Log().Error("Action execution failed", "runID", runID, "error", err)
-			// save error to error file
-			if _, writeErr := streams.Err().Write([]byte(err.Error())); writeErr != nil {
-				l.Log().Error("Failed to write error to stream", "error", writeErr)
+				l.stateMngr.removeActionState(runID)
 			}
-
-			l.stateMngr.removeActionState(runID)
+			status = l.finishRunRecord(runID, err)
+			l.metrics.runFinished(actionID, status, time.Since(startedAt))
+			finishRunSpan(span, status, err)
+			l.events.Publish(runEvent{Type: runEventFinished, ActionID: actionID, RunID: runID, Status: status})
+			return
+		case <-startCheck:
+			if ri, ok := l.actionMngr.RunInfoByID(runID); ok && ActionRunStatus(ri.Status) != status {
+				status = ActionRunStatus(ri.Status)
+				l.events.Publish(runEvent{Type: runEventStatus, ActionID: actionID, RunID: runID, Status: status})
+			}
+			if status != ActionRunStatusCreated {
+				// A nil channel never fires, the run has started.
+				startCheck = nil
+				continue
+			}
+			startCheckDelay = min(startCheckDelay*2, runStartCheckMax)
+			startCheck = time.After(startCheckDelay)
 		}
-	}()
+	}
+}
 
-	w.WriteHeader(http.StatusCreated)
-	_ = json.NewEncoder(w).Encode(ActionRunInfo{
-		ID:     ri.ID,
-		Status: ActionRunStatus(ri.Status),
+// finishRunRecord stores the final state of the run in history and returns the final status.
+func (l *launchrServer) finishRunRecord(runID string, runErr error) ActionRunStatus {
+	finishedAt := time.Now()
+	status := ActionRunStatusFinished
+	if ri, ok := l.actionMngr.RunInfoByID(runID); ok {
+		status = ActionRunStatus(ri.Status)
+	}
+	err := l.history.Update(runID, func(rec *ActionRunRecord) {
+		rec.FinishedAt = &finishedAt
+		rec.Status = status
+		if runErr != nil {
+			msg := runErr.Error()
+			rec.Error = &msg
+		}
 	})
+	if err != nil {
+		l.Log().Error("Failed to update run in history", "runID", runID, "error", err)
+	}
+	return status
 }
 
-func (l *launchrServer) apiActionFull(a *action.Action) (ActionFull, error) {
+func (l *launchrServer) apiActionFull(r *http.Request, a *action.Action) (ActionFull, error) {
 	short, err := l.apiActionShort(a)
 	if err != nil {
 		return ActionFull{}, err
 	}
-	actionSchema := a.JSONSchema()
-	actionSchema.ID = fmt.Sprintf("%s/actions/%s/schema.json", l.basePath(), url.QueryEscape(a.ID))
-
-	// As we don't have a full JSON schema from action. Need to populate it with runtime and persistent flags.
-	if rt, ok := a.Runtime().(action.RuntimeFlags); ok {
-		runtimeFlagsSchema := rt.GetFlags().JSONSchema()
-		actionSchema.Properties["runtime"] = runtimeFlagsSchema.Properties["runtime"]
-	}
-
-	// Add persistent flags into the action schema.
-	persistentFlagsSchema := l.actionMngr.GetPersistentFlags().JSONSchema()
-	actionSchema.Properties["persistent"] = persistentFlagsSchema.Properties["persistent"]
+	actionSchema := l.actionInputSchema(r, a)
 
 	uiSchema := koanf.New(".")
 
@@ -487,6 +726,34 @@ func (l *launchrServer) apiActionFull(a *action.Action) (ActionFull, error) {
 	}, nil
 }
 
+// actionInputSchema returns the JSON schema of the action input with runtime and persistent flags.
+func (l *launchrServer) actionInputSchema(r *http.Request, a *action.Action) jsonschema.Schema {
+	actionSchema := a.JSONSchema()
+	actionSchema.ID = fmt.Sprintf("%s/actions/%s/schema.json", l.apiURL(r), url.QueryEscape(a.ID))
+
+	// As we don't have a full JSON schema from action. Need to populate it with runtime and persistent flags.
+	if rt, ok := a.Runtime().(action.RuntimeFlags); ok {
+		runtimeFlagsSchema := rt.GetFlags().JSONSchema()
+		actionSchema.Properties["runtime"] = runtimeFlagsSchema.Properties["runtime"]
+	}
+
+	// Add persistent flags into the action schema.
+	persistentFlagsSchema := l.actionMngr.GetPersistentFlags().JSONSchema()
+	actionSchema.Properties["persistent"] = persistentFlagsSchema.Properties["persistent"]
+	return actionSchema
+}
+
+// safeRunParams masks sensitive values of the run params stored in the history and the audit log.
+// All values are masked if the params can't be sanitized.
+func (l *launchrServer) safeRunParams(schema jsonschema.Schema, params ActionRunParams) ActionRunParams {
+	sanitized, err := sanitizeRunParams(l.app, schema, params)
+	if err != nil {
+		l.Log().Error("Failed to sanitize run params, all values are masked", "error", err)
+		return redactRunParams(params)
+	}
+	return sanitized
+}
+
 func (l *launchrServer) apiActionShort(a *action.Action) (ActionShort, error) {
 	def := a.ActionDef()
 	return ActionShort{
@@ -506,7 +773,12 @@ func sendError(w http.ResponseWriter, code int, message string) {
 }
 
 // Use front-end changed property to filter out default arguments and options.
+// If the changed property is not sent, all the submitted values are used.
 func convertUserInput(a *action.Action, persistentFlagsDef action.ParametersList, params ActionRunParams) ActionRunParams {
+	if params.Changed == nil {
+		return params
+	}
+
 	changedArgs := make(map[string]bool)
 	changedOpts := make(map[string]bool)
 	changedRuntime := make(map[string]bool)
@@ -0,0 +1,300 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	chimw "github.com/go-chi/chi/v5/middleware"
+)
+
+const (
+	auditFilename = "audit.jsonl"
+
+	defaultAuditMaxSize    = 10 << 20
+	defaultAuditMaxBackups = 5
+	defaultAuditLimit      = 100
+
+	// auditReadBlock is a size of the blocks the log files are read backward by.
+	auditReadBlock = 32 << 10
+)
+
+// AuditOptions configures the audit log.
+type AuditOptions struct {
+	// DirPath is a directory of the audit log files.
+	DirPath string
+	// MaxSize is a size in bytes after which the log file is rotated.
+	MaxSize int64
+	// MaxBackups is a number of rotated files to keep.
+	MaxBackups int
+}
+
+// auditLog is an append-only JSON Lines log of the API mutations.
+// The current file is audit.jsonl, rotated files are audit.1.jsonl, audit.2.jsonl, etc., the lower the newer.
+type auditLog struct {
+	dir        string
+	maxSize    int64
+	maxBackups int
+
+	f    *os.File
+	size int64
+	mx   sync.Mutex
+}
+
+func newAuditLog(opts AuditOptions) (*auditLog, error) {
+	a := &auditLog{
+		dir:        opts.DirPath,
+		maxSize:    opts.MaxSize,
+		maxBackups: opts.MaxBackups,
+	}
+	if a.maxSize <= 0 {
+		a.maxSize = defaultAuditMaxSize
+	}
+	if a.maxBackups <= 0 {
+		a.maxBackups = defaultAuditMaxBackups
+	}
+	if err := os.MkdirAll(a.dir, 0750); err != nil {
+		return nil, fmt.Errorf("can't create audit dir: %w", err)
+	}
+	if err := a.open(); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+func (a *auditLog) filePath(n int) string {
+	if n == 0 {
+		return filepath.Join(a.dir, auditFilename)
+	}
+	return filepath.Join(a.dir, fmt.Sprintf("audit.%d.jsonl", n))
+}
+
+func (a *auditLog) open() error {
+	f, err := os.OpenFile(a.filePath(0), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("can't open audit log: %w", err)
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return fmt.Errorf("can't open audit log: %w", err)
+	}
+	a.f, a.size = f, fi.Size()
+	return nil
+}
+
+// rotate shifts the log files and starts a new one. The oldest file is removed.
+func (a *auditLog) rotate() error {
+	if err := a.f.Close(); err != nil {
+		return err
+	}
+	_ = os.Remove(a.filePath(a.maxBackups))
+	for i := a.maxBackups - 1; i >= 0; i-- {
+		err := os.Rename(a.filePath(i), a.filePath(i+1))
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return a.open()
+}
+
+// Write appends the record to the log.
+func (a *auditLog) Write(rec AuditRecord) error {
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+
+	a.mx.Lock()
+	defer a.mx.Unlock()
+	if a.size > 0 && a.size+int64(len(data)) > a.maxSize {
+		if err = a.rotate(); err != nil {
+			return fmt.Errorf("can't rotate audit log: %w", err)
+		}
+	}
+	n, err := a.f.Write(data)
+	a.size += int64(n)
+	return err
+}
+
+// List returns the records matching the filter, most recent first.
+// The files are read backward from the newest until the limit is reached.
+func (a *auditLog) List(filter func(rec AuditRecord) bool, limit int) ([]AuditRecord, error) {
+	a.mx.Lock()
+	defer a.mx.Unlock()
+
+	result := make([]AuditRecord, 0)
+	for i := 0; i <= a.maxBackups && len(result) < limit; i++ {
+		f, err := os.Open(a.filePath(i))
+		if os.IsNotExist(err) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		err = readLinesBackward(f, func(line []byte) bool {
+			var rec AuditRecord
+			if err := json.Unmarshal(line, &rec); err != nil {
+				// Skip a partially written line.
+				return true
+			}
+			if filter(rec) {
+				result = append(result, rec)
+			}
+			return len(result) < limit
+		})
+		_ = f.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// readLinesBackward calls fn for the lines of the file from the last one until fn returns false.
+// Only a block and the line being read are kept in memory.
+func readLinesBackward(f *os.File, fn func(line []byte) bool) error {
+	fi, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	offset := fi.Size()
+	var pending []byte
+	for offset > 0 {
+		n := min(int64(auditReadBlock), offset)
+		offset -= n
+		data := make([]byte, n, n+int64(len(pending)))
+		if _, err = f.ReadAt(data, offset); err != nil {
+			return err
+		}
+		data = append(data, pending...)
+		for {
+			i := bytes.LastIndexByte(data, '\n')
+			if i < 0 {
+				break
+			}
+			if line := data[i+1:]; len(line) > 0 && !fn(line) {
+				return nil
+			}
+			data = data[:i]
+		}
+		pending = data
+	}
+	if len(pending) > 0 {
+		fn(pending)
+	}
+	return nil
+}
+
+// Close closes the current log file.
+func (a *auditLog) Close() error {
+	a.mx.Lock()
+	defer a.mx.Unlock()
+	return a.f.Close()
+}
+
+type ctxKeyAudit struct{}
+
+// auditDetails are filled by the handlers to describe the subject of the request.
+type auditDetails struct {
+	User     string
+	ActionID string
+	RunID    string
+	Params   *ActionRunParams
+	// ReadOnly is set by the handlers that don't change anything, e.g. the input validation.
+	// The request isn't recorded then.
+	ReadOnly bool
+}
+
+// auditDetailsFromContext returns details of the audited request.
+// The details of not audited requests are discarded.
+func auditDetailsFromContext(ctx context.Context) *auditDetails {
+	if d, ok := ctx.Value(ctxKeyAudit{}).(*auditDetails); ok {
+		return d
+	}
+	return &auditDetails{}
+}
+
+// auditMiddleware records all API requests except reading and the requests marked read-only by the handlers.
+// It's mounted before the authentication to record the rejected requests too.
+func (l *launchrServer) auditMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet || r.Method == http.MethodHead || r.Method == http.MethodOptions:
+			next.ServeHTTP(w, r)
+			return
+		case !strings.HasPrefix(r.URL.Path, l.apiPrefix+"/"):
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		details := &auditDetails{}
+		ww := chimw.NewWrapResponseWriter(w, r.ProtoMajor)
+		next.ServeHTTP(ww, r.WithContext(context.WithValue(r.Context(), ctxKeyAudit{}, details)))
+		if details.ReadOnly {
+			return
+		}
+
+		rec := AuditRecord{
+			Time:       time.Now(),
+			RemoteAddr: l.public.clientAddr(r),
+			Endpoint:   r.Method + " " + r.URL.Path,
+			Status:     ww.Status(),
+			Params:     details.Params,
+		}
+		if rec.Status == 0 {
+			rec.Status = http.StatusOK
+		}
+		if details.User != "" {
+			rec.User = &details.User
+		}
+		if details.ActionID != "" {
+			rec.ActionID = &details.ActionID
+		}
+		if details.RunID != "" {
+			rec.RunID = &details.RunID
+		}
+		if err := l.audit.Write(rec); err != nil {
+			l.Log().Error("Failed to write audit record", "error", err)
+		}
+	})
+}
+
+func (l *launchrServer) GetAuditLog(w http.ResponseWriter, r *http.Request, params GetAuditLogParams) {
+	if !l.access.IsAdmin(identityFromContext(r.Context())) {
+		sendError(w, http.StatusForbidden, "Audit log is available to admins only")
+		return
+	}
+
+	limit := defaultAuditLimit
+	if params.Limit != nil {
+		limit = *params.Limit
+	}
+	records, err := l.audit.List(func(rec AuditRecord) bool {
+		if params.ActionId != nil && (rec.ActionID == nil || *rec.ActionID != *params.ActionId) {
+			return false
+		}
+		if params.User != nil && (rec.User == nil || *rec.User != *params.User) {
+			return false
+		}
+		return true
+	}, limit)
+	if err != nil {
+		l.Log().Error("Failed to read audit log", "error", err)
+		sendError(w, http.StatusInternalServerError, "Error reading audit log")
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(records)
+}
@@ -0,0 +1,116 @@
+package server
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestAuditLogList(t *testing.T) {
+	t.Parallel()
+
+	newRecord := func(i int) AuditRecord {
+		id := string(rune('0' + i))
+		return AuditRecord{Endpoint: "POST /api/actions/" + id + "/run", ActionID: &id}
+	}
+	data, err := json.Marshal(newRecord(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Every file holds 2 records, 8 records are written to 4 files, the oldest file is removed.
+	dir := t.TempDir()
+	a, err := newAuditLog(AuditOptions{DirPath: dir, MaxSize: int64(2 * (len(data) + 1)), MaxBackups: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = a.Close() })
+	for i := range 8 {
+		if err = a.Write(newRecord(i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err = os.Stat(filepath.Join(dir, "audit.3.jsonl")); !os.IsNotExist(err) {
+		t.Fatalf("expected the oldest file to be removed, got %v", err)
+	}
+
+	all := func(AuditRecord) bool { return true }
+	tests := []struct {
+		name   string
+		filter func(rec AuditRecord) bool
+		limit  int
+		expIDs []string
+	}{
+		{"all records", all, 100, []string{"7", "6", "5", "4", "3", "2"}},
+		{"limit in the current file", all, 1, []string{"7"}},
+		{"limit in a rotated file", all, 3, []string{"7", "6", "5"}},
+		{"filter", func(rec AuditRecord) bool { return *rec.ActionID == "2" || *rec.ActionID == "5" }, 100, []string{"5", "2"}},
+		{"filter with limit", func(rec AuditRecord) bool { return *rec.ActionID != "7" }, 2, []string{"6", "5"}},
+		{"nothing matches", func(AuditRecord) bool { return false }, 100, []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			records, err := a.List(tt.filter, tt.limit)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ids := make([]string, 0, len(records))
+			for _, rec := range records {
+				ids = append(ids, *rec.ActionID)
+			}
+			if !slices.Equal(ids, tt.expIDs) {
+				t.Fatalf("expected records %v, got %v", tt.expIDs, ids)
+			}
+		})
+	}
+}
+
+func TestReadLinesBackward(t *testing.T) {
+	t.Parallel()
+
+	long := strings.Repeat("x", auditReadBlock+10)
+	tests := []struct {
+		name    string
+		content string
+		stopAt  int
+		exp     []string
+	}{
+		{"empty file", "", 0, []string{}},
+		{"lines", "a\nb\nc\n", 0, []string{"c", "b", "a"}},
+		{"last line without newline", "a\nb", 0, []string{"b", "a"}},
+		{"empty lines", "\na\n\n\nb\n", 0, []string{"b", "a"}},
+		{"line longer than a block", "a\n" + long + "\nb\n", 0, []string{"b", long, "a"}},
+		{"lines over the block boundary", strings.Repeat("abc\n", auditReadBlock/3), 0, slices.Repeat([]string{"abc"}, auditReadBlock/3)},
+		{"stop", "a\nb\nc\n", 2, []string{"c", "b"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			path := filepath.Join(t.TempDir(), "lines")
+			if err := os.WriteFile(path, []byte(tt.content), 0600); err != nil {
+				t.Fatal(err)
+			}
+			f, err := os.Open(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+
+			lines := make([]string, 0)
+			err = readLinesBackward(f, func(line []byte) bool {
+				lines = append(lines, string(line))
+				return tt.stopAt == 0 || len(lines) < tt.stopAt
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(lines, tt.exp) {
+				t.Fatalf("expected %d lines %.40q, got %d lines %.40q", len(tt.exp), tt.exp, len(lines), lines)
+			}
+		})
+	}
+}
@@ -0,0 +1,265 @@
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"html/template"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	authCookieName = "launchr_web_token"
+	authTokenField = "token"
+	authCodeParam  = "code"
+
+	// loginCodeTTL is a time the one-time login code is valid after the server starts.
+	loginCodeTTL = 5 * time.Minute
+
+	loginPath  = "/login"
+	logoutPath = "/logout"
+)
+
+type ctxKeyIdentity struct{}
+
+// AuthToken is an API access token of a named user.
+type AuthToken struct {
+	Name  string `yaml:"name"`
+	Token string `yaml:"token"`
+}
+
+// LoginCode is a one-time code exchanged for the session of the user, e.g. in the URL opened in the browser.
+// Unlike the token, the code may appear in the command line and the browser history, it can't be used twice.
+type LoginCode struct {
+	Code string
+	// User is a name of the token the session is created with.
+	User string
+}
+
+// authenticator checks access tokens of the requests.
+type authenticator struct {
+	tokens []AuthToken
+	secure bool
+	public *publicURL
+
+	code        *LoginCode
+	codeExpires time.Time
+	codeMx      sync.Mutex
+}
+
+// useCode exchanges the one-time login code for the token of the user.
+func (a *authenticator) useCode(code string) (string, bool) {
+	a.codeMx.Lock()
+	defer a.codeMx.Unlock()
+	if a.code == nil || code == "" || time.Now().After(a.codeExpires) {
+		return "", false
+	}
+	if subtle.ConstantTimeCompare([]byte(a.code.Code), []byte(code)) != 1 {
+		return "", false
+	}
+	user := a.code.User
+	a.code = nil
+	for _, t := range a.tokens {
+		if t.Name == user {
+			return t.Token, true
+		}
+	}
+	return "", false
+}
+
+// identity returns the name of the token owner if the token is known.
+func (a *authenticator) identity(token string) (string, bool) {
+	if token == "" {
+		return "", false
+	}
+	// Check all tokens to not leak the position of the matching one.
+	var name string
+	var found bool
+	for _, t := range a.tokens {
+		if subtle.ConstantTimeCompare([]byte(t.Token), []byte(token)) == 1 {
+			name, found = t.Name, true
+		}
+	}
+	return name, found
+}
+
+// requestToken gets the token from the Authorization header or from the session cookie.
+// Browsers can't set headers for websocket and EventSource, the cookie is used for them.
+func requestToken(r *http.Request) string {
+	if h := r.Header.Get("Authorization"); h != "" {
+		if token, ok := strings.CutPrefix(h, "Bearer "); ok {
+			return strings.TrimSpace(token)
+		}
+	}
+	if c, err := r.Cookie(authCookieName); err == nil {
+		return c.Value
+	}
+	return ""
+}
+
+func (a *authenticator) setCookie(w http.ResponseWriter, r *http.Request, token string) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     authCookieName,
+		Value:    token,
+		Path:     a.public.path(r) + "/",
+		HttpOnly: true,
+		Secure:   a.secure || a.public.scheme(r) == "https",
+		SameSite: http.SameSiteStrictMode,
+	})
+}
+
+// middleware rejects requests without a valid token.
+// API and websocket requests get 401, page requests are redirected to the login page.
+func (a *authenticator) middleware(apiPrefix string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == loginPath || r.Method == http.MethodOptions {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			// A link with the one-time code logs in and removes the code from the address bar.
+			if code := r.URL.Query().Get(authCodeParam); code != "" && r.Method == http.MethodGet {
+				if token, ok := a.useCode(code); ok {
+					a.setCookie(w, r, token)
+					u := *r.URL
+					q := u.Query()
+					q.Del(authCodeParam)
+					u.RawQuery = q.Encode()
+					http.Redirect(w, r, a.public.path(r)+u.RequestURI(), http.StatusFound)
+					return
+				}
+			}
+
+			name, ok := a.identity(requestToken(r))
+			if !ok {
+				if strings.HasPrefix(r.URL.Path, apiPrefix+"/") || r.URL.Path == "/ws" || r.URL.Path == metricsPath {
+					sendError(w, http.StatusUnauthorized, "Unauthorized")
+					return
+				}
+				prefix := a.public.path(r)
+				http.Redirect(w, r, prefix+loginPath+"?next="+url.QueryEscape(prefix+r.URL.RequestURI()), http.StatusFound)
+				return
+			}
+
+			// The audit middleware wraps the authentication and doesn't see the identity in the context.
+			auditDetailsFromContext(r.Context()).User = name
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), ctxKeyIdentity{}, name)))
+		})
+	}
+}
+
+// identityFromContext returns the name of the authenticated user.
+func identityFromContext(ctx context.Context) string {
+	name, _ := ctx.Value(ctxKeyIdentity{}).(string)
+	return name
+}
+
+var loginTemplate = template.Must(template.New("login").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>Login</title>
+<style>
+body { font-family: sans-serif; display: flex; justify-content: center; margin-top: 15vh; }
+form { display: flex; flex-direction: column; gap: 0.75rem; width: 20rem; }
+input, button { padding: 0.5rem; font-size: 1rem; }
+.error { color: #b42318; }
+</style>
+</head>
+<body>
+<form method="post" action="{{.Action}}">
+<h2>Web UI login</h2>
+{{if .Error}}<div class="error">{{.Error}}</div>{{end}}
+<input type="password" name="token" placeholder="Access token" autofocus required>
+<input type="hidden" name="next" value="{{.Next}}">
+<button type="submit">Log in</button>
+</form>
+</body>
+</html>
+`))
+
+// safeRedirect allows only local paths under the prefix to prevent open redirects.
+func safeRedirect(next, prefix string) string {
+	if !strings.HasPrefix(next, prefix+"/") || strings.HasPrefix(next, "//") || strings.HasPrefix(next, "/\\") {
+		return prefix + "/"
+	}
+	return next
+}
+
+func (a *authenticator) loginHandler(w http.ResponseWriter, r *http.Request) {
+	prefix := a.public.path(r)
+	data := struct {
+		Action string
+		Next   string
+		Error  string
+	}{
+		Action: prefix + loginPath,
+		Next:   safeRedirect(r.URL.Query().Get("next"), prefix),
+	}
+
+	status := http.StatusOK
+	if r.Method == http.MethodPost {
+		data.Next = safeRedirect(r.PostFormValue("next"), prefix)
+		token := r.PostFormValue(authTokenField)
+		if _, ok := a.identity(token); ok {
+			a.setCookie(w, r, token)
+			http.Redirect(w, r, data.Next, http.StatusFound)
+			return
+		}
+		data.Error = "Invalid access token"
+		status = http.StatusUnauthorized
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	_ = loginTemplate.Execute(w, data)
+}
+
+func (a *authenticator) logoutHandler(w http.ResponseWriter, r *http.Request) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     authCookieName,
+		Value:    "",
+		Path:     a.public.path(r) + "/",
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   a.secure || a.public.scheme(r) == "https",
+		SameSite: http.SameSiteStrictMode,
+	})
+	http.Redirect(w, r, a.public.path(r)+loginPath, http.StatusFound)
+}
+
+// checkSameOrigin allows websocket connections only from the pages served by the server
+// or from the allowed hosts. Requests without Origin header don't come from browsers.
+func checkSameOrigin(r *http.Request, public *publicURL, allowed ...string) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	host := originHost(origin)
+	if host == "" {
+		return false
+	}
+	if strings.EqualFold(host, public.host(r)) {
+		return true
+	}
+	for _, a := range allowed {
+		if a != "" && strings.EqualFold(host, a) {
+			return true
+		}
+	}
+	return false
+}
+
+// originHost returns the host of the origin URL or an empty string if it's invalid.
+func originHost(origin string) string {
+	u, err := url.Parse(origin)
+	if err != nil {
+		return ""
+	}
+	return u.Host
+}
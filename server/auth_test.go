@@ -0,0 +1,151 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+	"time"
+)
+
+func TestUseCode(t *testing.T) {
+	t.Parallel()
+
+	tokens := []AuthToken{{Name: "alice", Token: "token-of-alice"}, {Name: "bob", Token: "token-of-bob"}}
+	tests := []struct {
+		name     string
+		code     *LoginCode
+		expires  time.Duration
+		use      string
+		expToken string
+		expOk    bool
+	}{
+		{"valid code", &LoginCode{Code: "abc", User: "bob"}, time.Minute, "abc", "token-of-bob", true},
+		{"wrong code", &LoginCode{Code: "abc", User: "bob"}, time.Minute, "abd", "", false},
+		{"empty code", &LoginCode{Code: "abc", User: "bob"}, time.Minute, "", "", false},
+		{"expired code", &LoginCode{Code: "abc", User: "bob"}, -time.Second, "abc", "", false},
+		{"no code", nil, time.Minute, "abc", "", false},
+		{"unknown user", &LoginCode{Code: "abc", User: "eve"}, time.Minute, "abc", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			a := &authenticator{tokens: tokens, code: tt.code, codeExpires: time.Now().Add(tt.expires)}
+			token, ok := a.useCode(tt.use)
+			if token != tt.expToken || ok != tt.expOk {
+				t.Fatalf("expected %q, %v, got %q, %v", tt.expToken, tt.expOk, token, ok)
+			}
+			// The code can't be used twice.
+			if _, ok = a.useCode(tt.use); ok {
+				t.Fatal("expected the code to be used only once")
+			}
+		})
+	}
+}
+
+func TestRequestToken(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		header string
+		cookie string
+		exp    string
+	}{
+		{"bearer header", "Bearer abc", "", "abc"},
+		{"bearer header with spaces", "Bearer  abc ", "", "abc"},
+		{"header takes precedence", "Bearer abc", "def", "abc"},
+		{"cookie", "", "def", "def"},
+		{"other scheme falls back to cookie", "Basic abc", "def", "def"},
+		{"nothing", "", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			r := httptest.NewRequest(http.MethodGet, "/api/actions", nil)
+			if tt.header != "" {
+				r.Header.Set("Authorization", tt.header)
+			}
+			if tt.cookie != "" {
+				r.AddCookie(&http.Cookie{Name: authCookieName, Value: tt.cookie})
+			}
+			if got := requestToken(r); got != tt.exp {
+				t.Fatalf("expected %q, got %q", tt.exp, got)
+			}
+		})
+	}
+}
+
+func TestSafeRedirect(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		next   string
+		prefix string
+		exp    string
+	}{
+		{"local path", "/actions/foo", "", "/actions/foo"},
+		{"local path with query", "/actions?q=1", "", "/actions?q=1"},
+		{"empty", "", "", "/"},
+		{"absolute url", "https://example.com/", "", "/"},
+		{"protocol relative url", "//example.com/", "", "/"},
+		{"backslash url", "/\\example.com/", "", "/"},
+		{"relative path", "actions", "", "/"},
+		{"path under the prefix", "/web/actions", "/web", "/web/actions"},
+		{"path outside of the prefix", "/other/actions", "/web", "/web/"},
+		{"path sharing the prefix", "/website", "/web", "/web/"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := safeRedirect(tt.next, tt.prefix); got != tt.exp {
+				t.Fatalf("expected %q, got %q", tt.exp, got)
+			}
+		})
+	}
+}
+
+func TestCheckSameOrigin(t *testing.T) {
+	t.Parallel()
+
+	trusted := []netip.Prefix{netip.MustParsePrefix("10.0.0.1/32")}
+	tests := []struct {
+		name       string
+		origin     string
+		remoteAddr string
+		fwdHost    string
+		allowed    []string
+		exp        bool
+	}{
+		{"no origin", "", "192.0.2.1:1234", "", nil, true},
+		{"same host", "http://example.com", "192.0.2.1:1234", "", nil, true},
+		{"same host different case", "http://EXAMPLE.com", "192.0.2.1:1234", "", nil, true},
+		{"other host", "http://evil.com", "192.0.2.1:1234", "", nil, false},
+		{"invalid origin", "://", "192.0.2.1:1234", "", nil, false},
+		{"null origin", "null", "192.0.2.1:1234", "", nil, false},
+		{"allowed host", "http://ui.example.com", "192.0.2.1:1234", "", []string{"ui.example.com"}, true},
+		{"forwarded host of a trusted proxy", "https://public.com", "10.0.0.1:1234", "public.com", nil, true},
+		{"forwarded host of an untrusted client", "https://public.com", "192.0.2.1:1234", "public.com", nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			public, err := newPublicURL("", "", trusted)
+			if err != nil {
+				t.Fatal(err)
+			}
+			r := httptest.NewRequest(http.MethodGet, "http://example.com/ws", nil)
+			r.RemoteAddr = tt.remoteAddr
+			if tt.origin != "" {
+				r.Header.Set("Origin", tt.origin)
+			}
+			if tt.fwdHost != "" {
+				r.Header.Set("X-Forwarded-Host", tt.fwdHost)
+			}
+			if got := checkSameOrigin(r, public, tt.allowed...); got != tt.exp {
+				t.Fatalf("expected %v, got %v", tt.exp, got)
+			}
+		})
+	}
+}
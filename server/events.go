@@ -0,0 +1,105 @@
+package server
+
+import (
+	"sync"
+)
+
+// maxPendingEvents limits the queue of a slow subscriber. Output events are dropped on overflow,
+// lifecycle events are always delivered.
+const maxPendingEvents = 1024
+
+type runEventType string
+
+const (
+	runEventCreated  runEventType = "created"
+	runEventStatus   runEventType = "status"
+	runEventOutput   runEventType = "output"
+	runEventFinished runEventType = "finished"
+)
+
+// runEvent is a notification about a change in the action run lifecycle.
+type runEvent struct {
+	Type     runEventType
+	ActionID string
+	RunID    string
+	Status   ActionRunStatus
+	// Output is set for output events.
+	Output *ActionRunStreamData
+}
+
+// eventHub fans out run events to subscribers.
+type eventHub struct {
+	subs map[*eventSubscriber]struct{}
+	mx   sync.RWMutex
+}
+
+func newEventHub() *eventHub {
+	return &eventHub{
+		subs: make(map[*eventSubscriber]struct{}),
+	}
+}
+
+// Subscribe registers a subscriber receiving events matching the filter.
+func (h *eventHub) Subscribe(filter func(ev runEvent) bool) *eventSubscriber {
+	s := &eventSubscriber{
+		filter: filter,
+		notify: make(chan struct{}, 1),
+	}
+	h.mx.Lock()
+	h.subs[s] = struct{}{}
+	h.mx.Unlock()
+	return s
+}
+
+// Unsubscribe stops delivering events to the subscriber.
+func (h *eventHub) Unsubscribe(s *eventSubscriber) {
+	h.mx.Lock()
+	delete(h.subs, s)
+	h.mx.Unlock()
+}
+
+// Publish sends the event to all interested subscribers. It never blocks on slow subscribers.
+func (h *eventHub) Publish(ev runEvent) {
+	h.mx.RLock()
+	defer h.mx.RUnlock()
+	for s := range h.subs {
+		if s.filter == nil || s.filter(ev) {
+			s.push(ev)
+		}
+	}
+}
+
+// eventSubscriber is a queue of events for a single consumer.
+type eventSubscriber struct {
+	filter  func(ev runEvent) bool
+	notify  chan struct{}
+	pending []runEvent
+	mx      sync.Mutex
+}
+
+func (s *eventSubscriber) push(ev runEvent) {
+	s.mx.Lock()
+	if ev.Type != runEventOutput || len(s.pending) < maxPendingEvents {
+		s.pending = append(s.pending, ev)
+	}
+	s.mx.Unlock()
+
+	select {
+	case s.notify <- struct{}{}:
+	default:
+	}
+}
+
+// Notify returns a channel signaling that new events are available.
+func (s *eventSubscriber) Notify() <-chan struct{} {
+	return s.notify
+}
+
+// Events returns and removes all pending events.
+func (s *eventSubscriber) Events() []runEvent {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	events := s.pending
+	s.pending = nil
+	return events
+}
@@ -0,0 +1,354 @@
+package server
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/launchrctl/launchr"
+	"github.com/launchrctl/launchr/pkg/action"
+	"github.com/launchrctl/launchr/pkg/jsonschema"
+)
+
+const (
+	runRecordExt = ".json"
+
+	defaultRunsLimit = 50
+	// maxRunsLimit matches the maximum of the limit parameter in the spec.
+	maxRunsLimit = 100
+)
+
+var (
+	errInvalidCursor = errors.New("invalid cursor")
+	errInvalidLimit  = fmt.Errorf("invalid limit, a number from 1 to %d is expected", maxRunsLimit)
+)
+
+// errInterruptedRun is stored for runs that were not finished when the server stopped.
+const errInterruptedRun = "the server was stopped before the run finished"
+
+// runHistory is a persistent storage of action runs.
+// Every run is stored in a separate JSON file, so a partially written file
+// never corrupts the rest of the history.
+type runHistory struct {
+	dir  string
+	runs map[string]ActionRunRecord
+	mx   sync.RWMutex
+}
+
+// newRunHistory opens the run history in the directory and loads previously stored runs.
+func newRunHistory(dir string) (*runHistory, error) {
+	err := os.MkdirAll(dir, 0750)
+	if err != nil {
+		return nil, fmt.Errorf("can't create run history dir: %w", err)
+	}
+
+	h := &runHistory{
+		dir:  dir,
+		runs: make(map[string]ActionRunRecord),
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("can't read run history dir: %w", err)
+	}
+	for _, e := range entries {
+		if e.IsDir() || filepath.Ext(e.Name()) != runRecordExt {
+			continue
+		}
+		rec, err := h.read(filepath.Join(dir, e.Name()))
+		if err != nil {
+			launchr.Log().Warn("skipping broken run history record", "file", e.Name(), "error", err)
+			continue
+		}
+		// The run can't be active if the server has just started.
+		if rec.FinishedAt == nil {
+			rec.Status = ActionRunStatusError
+			rec.FinishedAt = &rec.StartedAt
+			msg := errInterruptedRun
+			rec.Error = &msg
+			if err = h.write(rec); err != nil {
+				return nil, err
+			}
+		}
+		h.runs[rec.ID] = rec
+	}
+
+	return h, nil
+}
+
+// Save creates or replaces the run record.
+func (h *runHistory) Save(rec ActionRunRecord) error {
+	h.mx.Lock()
+	defer h.mx.Unlock()
+	if err := h.write(rec); err != nil {
+		return err
+	}
+	h.runs[rec.ID] = rec
+	return nil
+}
+
+// Update applies fn to the stored run record and saves the result.
+func (h *runHistory) Update(id string, fn func(rec *ActionRunRecord)) error {
+	h.mx.Lock()
+	defer h.mx.Unlock()
+	rec, ok := h.runs[id]
+	if !ok {
+		return fmt.Errorf("run %q is not found in history", id)
+	}
+	fn(&rec)
+	if err := h.write(rec); err != nil {
+		return err
+	}
+	h.runs[id] = rec
+	return nil
+}
+
+// Get returns the run record by run id.
+func (h *runHistory) Get(id string) (ActionRunRecord, bool) {
+	h.mx.RLock()
+	defer h.mx.RUnlock()
+	rec, ok := h.runs[id]
+	return rec, ok
+}
+
+// List returns all run records, the most recent first.
+func (h *runHistory) List() []ActionRunRecord {
+	h.mx.RLock()
+	result := make([]ActionRunRecord, 0, len(h.runs))
+	for _, rec := range h.runs {
+		result = append(result, rec)
+	}
+	h.mx.RUnlock()
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].StartedAt.Equal(result[j].StartedAt) {
+			return result[i].ID > result[j].ID
+		}
+		return result[i].StartedAt.After(result[j].StartedAt)
+	})
+	return result
+}
+
+func (h *runHistory) path(id string) string {
+	// Action ids may contain characters which are not allowed in file names on some platforms.
+	name := strings.NewReplacer(":", "_", "/", "_", "\\", "_").Replace(id)
+	return filepath.Join(h.dir, name+runRecordExt)
+}
+
+func (h *runHistory) read(path string) (ActionRunRecord, error) {
+	var rec ActionRunRecord
+	data, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return rec, err
+	}
+	err = json.Unmarshal(data, &rec)
+	return rec, err
+}
+
+func (h *runHistory) write(rec ActionRunRecord) error {
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	// Write to a temporary file first to replace the record atomically.
+	path := h.path(rec.ID)
+	tmp := path + ".tmp"
+	if err = os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("can't write run history record: %w", err)
+	}
+	if err = os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("can't write run history record: %w", err)
+	}
+	return nil
+}
+
+// runsFilter returns true if the run record matches the query params.
+func runsFilter(params GetRunsParams) func(rec ActionRunRecord) bool {
+	return func(rec ActionRunRecord) bool {
+		if params.ActionId != nil && *params.ActionId != "" && rec.ActionID != *params.ActionId {
+			return false
+		}
+		if params.Status != nil && len(*params.Status) > 0 && !slices.Contains(*params.Status, rec.Status) {
+			return false
+		}
+		if params.From != nil && rec.StartedAt.Before(*params.From) {
+			return false
+		}
+		if params.To != nil && !rec.StartedAt.Before(*params.To) {
+			return false
+		}
+		if params.Q != nil && *params.Q != "" {
+			q := strings.ToLower(*params.Q)
+			text := rec.ID + "\n" + rec.ActionID
+			if rec.Error != nil {
+				text += "\n" + *rec.Error
+			}
+			if !strings.Contains(strings.ToLower(text), q) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// paginateRuns returns a page of sorted run records and a cursor for the next page.
+// Records must be sorted the most recent first.
+// The API validator rejects limits out of the range of the spec with 400, the same range is checked here.
+func paginateRuns(records []ActionRunRecord, params GetRunsParams) ([]ActionRunRecord, *string, error) {
+	limit := defaultRunsLimit
+	if params.Limit != nil {
+		limit = *params.Limit
+	}
+	if limit < 1 || limit > maxRunsLimit {
+		return nil, nil, errInvalidLimit
+	}
+
+	if params.Sort != nil && *params.Sort == Asc {
+		slices.Reverse(records)
+	}
+
+	if params.Cursor != nil && *params.Cursor != "" {
+		id, err := decodeRunsCursor(*params.Cursor)
+		if err != nil {
+			return nil, nil, err
+		}
+		i := slices.IndexFunc(records, func(rec ActionRunRecord) bool {
+			return rec.ID == id
+		})
+		if i == -1 {
+			return nil, nil, errInvalidCursor
+		}
+		records = records[i+1:]
+	}
+
+	if len(records) <= limit {
+		return records, nil, nil
+	}
+
+	records = records[:limit]
+	next := encodeRunsCursor(records[len(records)-1].ID)
+	return records, &next, nil
+}
+
+// encodeRunsCursor builds an opaque cursor pointing to the run.
+func encodeRunsCursor(id string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.Quote(id)))
+}
+
+func decodeRunsCursor(cursor string) (string, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return "", errInvalidCursor
+	}
+	id, err := strconv.Unquote(string(data))
+	if err != nil {
+		return "", errInvalidCursor
+	}
+	return id, nil
+}
+
+// redactedValue replaces the sensitive values of the run params.
+const redactedValue = "****"
+
+// sensitiveFieldRegexp matches the names and titles of the fields holding secrets.
+var sensitiveFieldRegexp = regexp.MustCompile(`(?i)pass|secret|token`)
+
+// sanitizeRunParams masks sensitive values in the run params before they are persisted.
+// The fields marked as passwords in the schema or named like secrets are masked entirely,
+// the values registered as sensitive in the app are masked in all fields.
+func sanitizeRunParams(app launchr.App, schema jsonschema.Schema, params ActionRunParams) (ActionRunParams, error) {
+	props, err := toJSONValue(schema.Properties)
+	if err != nil {
+		return ActionRunParams{}, err
+	}
+	groups, _ := props.(map[string]any)
+	params.Arguments = redactSensitiveFields(params.Arguments, groups["arguments"])
+	params.Options = redactSensitiveFields(params.Options, groups["options"])
+	params.Runtime = redactSensitiveFields(params.Runtime, groups["runtime"])
+	params.Persistent = redactSensitiveFields(params.Persistent, groups["persistent"])
+
+	data, err := json.Marshal(params)
+	if err != nil {
+		return ActionRunParams{}, err
+	}
+	var buf bytes.Buffer
+	if _, err = app.SensitiveWriter(&buf).Write(data); err != nil {
+		return ActionRunParams{}, err
+	}
+	var sanitized ActionRunParams
+	if err = json.Unmarshal(buf.Bytes(), &sanitized); err != nil {
+		return ActionRunParams{}, err
+	}
+	return sanitized, nil
+}
+
+// redactSensitiveFields returns a copy of the values with the sensitive fields of the group schema masked.
+func redactSensitiveFields(values action.InputParams, groupSchema any) action.InputParams {
+	if values == nil {
+		return nil
+	}
+	var fields map[string]any
+	if group, ok := groupSchema.(map[string]any); ok {
+		fields, _ = group["properties"].(map[string]any)
+	}
+	result := make(action.InputParams, len(values))
+	for name, v := range values {
+		field, _ := fields[name].(map[string]any)
+		if isSensitiveField(name, field) {
+			v = redactedValue
+		}
+		result[name] = v
+	}
+	return result
+}
+
+// isSensitiveField checks if the field holds a secret by its name and schema.
+func isSensitiveField(name string, field map[string]any) bool {
+	if field["format"] == "password" || field["writeOnly"] == true {
+		return true
+	}
+	title, _ := field["title"].(string)
+	return sensitiveFieldRegexp.MatchString(name) || sensitiveFieldRegexp.MatchString(title)
+}
+
+// redactRunParams masks all values of the run params, the names of the fields are kept.
+func redactRunParams(params ActionRunParams) ActionRunParams {
+	redactAll := func(values action.InputParams) action.InputParams {
+		if values == nil {
+			return nil
+		}
+		result := make(action.InputParams, len(values))
+		for name := range values {
+			result[name] = redactedValue
+		}
+		return result
+	}
+	params.Arguments = redactAll(params.Arguments)
+	params.Options = redactAll(params.Options)
+	params.Runtime = redactAll(params.Runtime)
+	params.Persistent = redactAll(params.Persistent)
+	return params
+}
+
+// newRunRecord creates a history record for a just started run.
+func newRunRecord(ri ActionRunInfo, actionID string, params ActionRunParams, logs ActionRunLogs) ActionRunRecord {
+	return ActionRunRecord{
+		ID:        ri.ID,
+		Status:    ri.Status,
+		ActionID:  actionID,
+		Params:    params,
+		StartedAt: time.Now(),
+		Logs:      logs,
+	}
+}
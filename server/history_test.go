@@ -0,0 +1,138 @@
+package server
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestPaginateRuns(t *testing.T) {
+	t.Parallel()
+
+	// Records are sorted the most recent first like runHistory.List returns them.
+	newRecords := func() []ActionRunRecord {
+		return []ActionRunRecord{{ID: "5"}, {ID: "4"}, {ID: "3"}, {ID: "2"}, {ID: "1"}}
+	}
+	ptr := func(v int) *int { return &v }
+	cursor := func(id string) *string {
+		c := encodeRunsCursor(id)
+		return &c
+	}
+	asc := Asc
+	desc := Desc
+	invalid := "not a cursor"
+	unknown := encodeRunsCursor("unknown")
+
+	tests := []struct {
+		name    string
+		params  GetRunsParams
+		expIDs  []string
+		expNext *string
+		expErr  error
+	}{
+		{"default limit", GetRunsParams{}, []string{"5", "4", "3", "2", "1"}, nil, nil},
+		{"first page", GetRunsParams{Limit: ptr(2)}, []string{"5", "4"}, cursor("4"), nil},
+		{"next page", GetRunsParams{Limit: ptr(2), Cursor: cursor("4")}, []string{"3", "2"}, cursor("2"), nil},
+		{"last page", GetRunsParams{Limit: ptr(2), Cursor: cursor("2")}, []string{"1"}, nil, nil},
+		{"cursor of the last record", GetRunsParams{Cursor: cursor("1")}, []string{}, nil, nil},
+		{"exact page size", GetRunsParams{Limit: ptr(5)}, []string{"5", "4", "3", "2", "1"}, nil, nil},
+		{"sort desc", GetRunsParams{Sort: &desc, Limit: ptr(2)}, []string{"5", "4"}, cursor("4"), nil},
+		{"sort asc", GetRunsParams{Sort: &asc, Limit: ptr(2)}, []string{"1", "2"}, cursor("2"), nil},
+		{"sort asc next page", GetRunsParams{Sort: &asc, Limit: ptr(2), Cursor: cursor("2")}, []string{"3", "4"}, cursor("4"), nil},
+		{"zero limit", GetRunsParams{Limit: ptr(0)}, nil, nil, errInvalidLimit},
+		{"negative limit", GetRunsParams{Limit: ptr(-1)}, nil, nil, errInvalidLimit},
+		{"limit above maximum", GetRunsParams{Limit: ptr(maxRunsLimit + 1)}, nil, nil, errInvalidLimit},
+		{"malformed cursor", GetRunsParams{Cursor: &invalid}, nil, nil, errInvalidCursor},
+		{"unknown cursor", GetRunsParams{Cursor: &unknown}, nil, nil, errInvalidCursor},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			page, next, err := paginateRuns(newRecords(), tt.params)
+			if !errors.Is(err, tt.expErr) {
+				t.Fatalf("expected error %v, got %v", tt.expErr, err)
+			}
+			if tt.expErr != nil {
+				return
+			}
+			ids := make([]string, 0, len(page))
+			for _, rec := range page {
+				ids = append(ids, rec.ID)
+			}
+			if !slices.Equal(ids, tt.expIDs) {
+				t.Errorf("expected runs %v, got %v", tt.expIDs, ids)
+			}
+			if (next == nil) != (tt.expNext == nil) || next != nil && *next != *tt.expNext {
+				t.Errorf("expected next cursor %v, got %v", deref(tt.expNext), deref(next))
+			}
+		})
+	}
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return "<nil>"
+	}
+	return *s
+}
+
+func TestRedactSensitiveFields(t *testing.T) {
+	t.Parallel()
+
+	group := map[string]any{
+		"properties": map[string]any{
+			"host":     map[string]any{"type": "string", "title": "Host"},
+			"key":      map[string]any{"type": "string", "format": "password"},
+			"code":     map[string]any{"type": "string", "writeOnly": true},
+			"phrase":   map[string]any{"type": "string", "title": "SSH Passphrase"},
+			"api_key":  map[string]any{"type": "string", "title": "API Secret"},
+			"replicas": map[string]any{"type": "integer"},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		field    string
+		value    any
+		expected any
+	}{
+		{"plain field", "host", "example.com", "example.com"},
+		{"password format", "key", "s3cret", redactedValue},
+		{"write only", "code", "1234", redactedValue},
+		{"title with passphrase", "phrase", "s3cret", redactedValue},
+		{"title with secret", "api_key", "s3cret", redactedValue},
+		{"name with password", "db_password", "s3cret", redactedValue},
+		{"name with token not in schema", "GitHubToken", "s3cret", redactedValue},
+		{"not a string", "replicas", 3, 3},
+		{"unknown field", "region", "eu", "eu"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			values := map[string]any{tt.field: tt.value}
+			result := redactSensitiveFields(values, group)
+			if result[tt.field] != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, result[tt.field])
+			}
+			if values[tt.field] != tt.value {
+				t.Errorf("the input is modified: %v", values[tt.field])
+			}
+		})
+	}
+}
+
+func TestRedactRunParams(t *testing.T) {
+	t.Parallel()
+
+	params := ActionRunParams{
+		Arguments: map[string]any{"host": "example.com"},
+		Options:   map[string]any{"replicas": 3},
+	}
+	result := redactRunParams(params)
+	if result.Arguments["host"] != redactedValue || result.Options["replicas"] != redactedValue {
+		t.Errorf("expected all values to be masked, got %v and %v", result.Arguments, result.Options)
+	}
+	if result.Runtime != nil || result.Persistent != nil {
+		t.Errorf("expected empty groups to stay empty, got %v and %v", result.Runtime, result.Persistent)
+	}
+}
@@ -0,0 +1,73 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// idleWatcher shuts the server down when nobody uses it for the timeout.
+// The server is idle when there are no requests, no websocket connections and no running actions.
+type idleWatcher struct {
+	timeout time.Duration
+	// last is a time of the last activity in unix nanoseconds.
+	last atomic.Int64
+	// requests is a number of the requests in progress including websocket connections and event streams.
+	requests atomic.Int64
+}
+
+func newIdleWatcher(timeout time.Duration) *idleWatcher {
+	w := &idleWatcher{timeout: timeout}
+	w.touch()
+	return w
+}
+
+func (w *idleWatcher) touch() {
+	w.last.Store(time.Now().UnixNano())
+}
+
+// middleware registers the activity of the requests.
+// Health and status checks don't keep the server alive.
+func (w *idleWatcher) middleware(apiPrefix string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodHead || r.URL.Path == apiPrefix+"/status" {
+				next.ServeHTTP(rw, r)
+				return
+			}
+			w.requests.Add(1)
+			w.touch()
+			defer func() {
+				w.touch()
+				w.requests.Add(-1)
+			}()
+			next.ServeHTTP(rw, r)
+		})
+	}
+}
+
+// watch calls shutdown when the server is idle for the timeout.
+func (w *idleWatcher) watch(ctx context.Context, l *launchrServer, shutdown context.CancelFunc) {
+	ticker := time.NewTicker(max(min(w.timeout/10, time.Minute), time.Second))
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if w.requests.Load() > 0 || l.runs.active() > 0 {
+				w.touch()
+				continue
+			}
+			idle := time.Since(time.Unix(0, w.last.Load()))
+			if idle < w.timeout {
+				continue
+			}
+			l.Log().Info("shutting down idle server", "idle", idle.Round(time.Second).String(), "timeout", w.timeout.String())
+			l.Term().Info().Printfln("No activity for %s, shutting down.", w.timeout)
+			shutdown()
+			return
+		}
+	}
+}
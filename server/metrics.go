@@ -0,0 +1,144 @@
+package server
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/go-chi/chi/v5"
+	chimw "github.com/go-chi/chi/v5/middleware"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const (
+	metricsPath      = "/metrics"
+	metricsNamespace = "launchr_web"
+)
+
+// serverMetrics are Prometheus metrics of the server and the action runs.
+type serverMetrics struct {
+	registry *prometheus.Registry
+	// operations maps "METHOD /path/{param}" routes to the OpenAPI operation IDs.
+	operations map[string]string
+
+	requests        *prometheus.CounterVec
+	requestDuration *prometheus.HistogramVec
+	runsStarted     *prometheus.CounterVec
+	runsFinished    *prometheus.CounterVec
+	runDuration     *prometheus.HistogramVec
+	runsRunning     prometheus.Gauge
+	wsConnections   prometheus.Gauge
+}
+
+func newServerMetrics(swagger *openapi3.T, apiPrefix string) *serverMetrics {
+	m := &serverMetrics{
+		registry:   prometheus.NewRegistry(),
+		operations: make(map[string]string),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "http_requests_total",
+			Help:      "Number of HTTP requests by operation, method and status code.",
+		}, []string{"operation", "method", "code"}),
+		requestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "Duration of HTTP requests by operation and method.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"operation", "method"}),
+		runsStarted: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "runs_started_total",
+			Help:      "Number of started action runs by action.",
+		}, []string{"action"}),
+		runsFinished: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "runs_finished_total",
+			Help:      "Number of finished action runs by action and final status.",
+		}, []string{"action", "status"}),
+		runDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Name:      "run_duration_seconds",
+			Help:      "Duration of action runs by action and final status.",
+			Buckets:   []float64{1, 5, 15, 30, 60, 120, 300, 600, 1800, 3600},
+		}, []string{"action", "status"}),
+		runsRunning: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "runs_running",
+			Help:      "Number of currently running actions.",
+		}),
+		wsConnections: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "websocket_connections",
+			Help:      "Number of open websocket connections.",
+		}),
+	}
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.requests,
+		m.requestDuration,
+		m.runsStarted,
+		m.runsFinished,
+		m.runDuration,
+		m.runsRunning,
+		m.wsConnections,
+	)
+
+	for p, item := range swagger.Paths.Map() {
+		for method, op := range item.Operations() {
+			m.operations[method+" "+apiPrefix+p] = op.OperationID
+		}
+	}
+	return m
+}
+
+// operation returns the operation ID of the matched route.
+// Routes outside of the API are labeled by their pattern to keep the number of labels bounded.
+func (m *serverMetrics) operation(r *http.Request) string {
+	pattern := chi.RouteContext(r.Context()).RoutePattern()
+	if op, ok := m.operations[r.Method+" "+pattern]; ok {
+		return op
+	}
+	if pattern == "" {
+		return "unknown"
+	}
+	return pattern
+}
+
+// middleware counts requests and measures their duration.
+func (m *serverMetrics) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		ww := chimw.NewWrapResponseWriter(w, r.ProtoMajor)
+		next.ServeHTTP(ww, r)
+
+		code := ww.Status()
+		if code == 0 {
+			code = http.StatusOK
+		}
+		op := m.operation(r)
+		m.requests.WithLabelValues(op, r.Method, strconv.Itoa(code)).Inc()
+		// Streams live as long as the client is connected, their duration isn't a latency.
+		if op != "getRunningActionEvents" && op != "/ws" {
+			m.requestDuration.WithLabelValues(op, r.Method).Observe(time.Since(start).Seconds())
+		}
+	})
+}
+
+func (m *serverMetrics) runStarted(actionID string) {
+	m.runsStarted.WithLabelValues(actionID).Inc()
+	m.runsRunning.Inc()
+}
+
+func (m *serverMetrics) runFinished(actionID string, status ActionRunStatus, duration time.Duration) {
+	m.runsFinished.WithLabelValues(actionID, string(status)).Inc()
+	m.runDuration.WithLabelValues(actionID, string(status)).Observe(duration.Seconds())
+	m.runsRunning.Dec()
+}
+
+func (m *serverMetrics) handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
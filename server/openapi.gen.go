@@ -12,6 +12,7 @@ import (
 	"net/url"
 	"path"
 	"strings"
+	"time"
 
 	"github.com/getkin/kin-openapi/openapi3"
 	"github.com/go-chi/chi/v5"
@@ -36,6 +37,12 @@ const (
 	StdOut ActionRunStreamDataType = "stdOut"
 )
 
+// Defines values for GetRunsParamsSort.
+const (
+	Asc  GetRunsParamsSort = "asc"
+	Desc GetRunsParamsSort = "desc"
+)
+
 // ActionFull defines model for ActionFull.
 type ActionFull struct {
 	Description string                 `json:"description"`
@@ -51,6 +58,12 @@ type ActionRunInfo struct {
 	Status ActionRunStatus `json:"status"`
 }
 
+// ActionRunLogs defines model for ActionRunLogs.
+type ActionRunLogs struct {
+	StdErr string `json:"stdErr"`
+	StdOut string `json:"stdOut"`
+}
+
 // ActionRunParams defines model for ActionRunParams.
 type ActionRunParams struct {
 	Arguments  action.InputParams `json:"arguments"`
@@ -60,15 +73,39 @@ type ActionRunParams struct {
 	Runtime    action.InputParams `json:"runtime"`
 }
 
+// ActionRunRecord defines model for ActionRunRecord.
+type ActionRunRecord struct {
+	ActionID   string          `json:"actionId"`
+	Error      *string         `json:"error,omitempty"`
+	FinishedAt *time.Time      `json:"finishedAt,omitempty"`
+	ID         string          `json:"id"`
+	Logs       ActionRunLogs   `json:"logs"`
+	Params     ActionRunParams `json:"params"`
+	StartedAt  time.Time       `json:"startedAt"`
+	Status     ActionRunStatus `json:"status"`
+}
+
+// ActionRunRecordList defines model for ActionRunRecordList.
+type ActionRunRecordList struct {
+	Items []ActionRunRecord `json:"items"`
+
+	// NextCursor cursor to fetch the next page, absent on the last page
+	NextCursor *string `json:"nextCursor,omitempty"`
+}
+
 // ActionRunStatus defines model for ActionRunStatus.
 type ActionRunStatus string
 
 // ActionRunStreamData defines model for ActionRunStreamData.
 type ActionRunStreamData struct {
-	Content string                  `json:"content"`
-	Count   int                     `json:"count"`
-	Offset  int                     `json:"offset"`
-	Type    ActionRunStreamDataType `json:"type"`
+	Content string `json:"content"`
+
+	// Count number of bytes in the content, offset + count is the offset to request the next chunk
+	Count int `json:"count"`
+
+	// Offset byte offset of the content in the stream
+	Offset int                     `json:"offset"`
+	Type   ActionRunStreamDataType `json:"type"`
 }
 
 // ActionRunStreamDataType defines model for ActionRunStreamData.Type.
@@ -81,6 +118,26 @@ type ActionShort struct {
 	Title       string `json:"title"`
 }
 
+// AuditRecord defines model for AuditRecord.
+type AuditRecord struct {
+	ActionID *string `json:"actionId,omitempty"`
+
+	// Endpoint HTTP method and path of the request
+	Endpoint string           `json:"endpoint"`
+	Params   *ActionRunParams `json:"params,omitempty"`
+
+	// RemoteAddr address of the client
+	RemoteAddr string  `json:"remoteAddr"`
+	RunID      *string `json:"runId,omitempty"`
+
+	// Status status code of the response
+	Status int       `json:"status"`
+	Time   time.Time `json:"time"`
+
+	// User authenticated user
+	User *string `json:"user,omitempty"`
+}
+
 // CustomisationConfig defines model for Customisation.
 type CustomisationConfig = map[string]interface{}
 
@@ -90,9 +147,39 @@ type Error struct {
 	Message string `json:"message"`
 }
 
+// InputFieldError defines model for InputFieldError.
+type InputFieldError struct {
+	// Keyword JSON schema keyword the value violates, "unknown" if the action rejects the input for another reason
+	Keyword string `json:"keyword"`
+	Message string `json:"message"`
+
+	// Pointer JSON pointer to the invalid value, e.g. /options/port
+	Pointer string `json:"pointer"`
+}
+
+// InputValidationError defines model for InputValidationError.
+type InputValidationError struct {
+	Code    int               `json:"code"`
+	Errors  []InputFieldError `json:"errors"`
+	Message string            `json:"message"`
+}
+
 // JSONSchema defines model for JSONSchema.
 type JSONSchema = jsonschema.Schema
 
+// ServerStatus defines model for ServerStatus.
+type ServerStatus struct {
+	// Pid process id of the server
+	Pid int `json:"pid"`
+
+	// RunningActions number of currently running actions
+	RunningActions int       `json:"runningActions"`
+	StartedAt      time.Time `json:"startedAt"`
+
+	// Version version of the app
+	Version string `json:"version"`
+}
+
 // WizardFull defines model for WizardFull.
 type WizardFull struct {
 	Description string       `json:"description"`
@@ -129,24 +216,90 @@ type Limit = int
 // Offset defines model for Offset.
 type Offset = int
 
+// StreamLimit defines model for StreamLimit.
+type StreamLimit = int
+
+// StreamOffset defines model for StreamOffset.
+type StreamOffset = int
+
+// StreamType defines model for StreamType.
+type StreamType = string
+
 // WizardId defines model for WizardId.
 type WizardId = string
 
 // DefaultError defines model for DefaultError.
 type DefaultError = Error
 
+// InvalidInputError defines model for InvalidInputError.
+type InvalidInputError = InputValidationError
+
+// GetRunningActionEventsParams defines parameters for GetRunningActionEvents.
+type GetRunningActionEventsParams struct {
+	// LastEventID id of the last received event to resume the stream after it
+	LastEventID *string `json:"Last-Event-ID,omitempty"`
+}
+
 // GetRunningActionStreamsParams defines parameters for GetRunningActionStreams.
 type GetRunningActionStreamsParams struct {
-	// Offset number of elements to skip
-	Offset *Offset `form:"offset,omitempty" json:"offset,omitempty"`
+	// Type type of the stream to return, stdOut or stdErr, all streams are returned if omitted
+	Type *StreamType `form:"type,omitempty" json:"type,omitempty"`
+
+	// Offset number of bytes to skip from the beginning of the stream
+	Offset *StreamOffset `form:"offset,omitempty" json:"offset,omitempty"`
+
+	// Limit maximum number of bytes to return, the whole rest of the stream is returned if omitted
+	Limit *StreamLimit `form:"limit,omitempty" json:"limit,omitempty"`
+}
+
+// GetAuditLogParams defines parameters for GetAuditLog.
+type GetAuditLogParams struct {
+	// ActionId return only records of the action
+	ActionId *string `form:"actionId,omitempty" json:"actionId,omitempty"`
+
+	// User return only records of the user
+	User *string `form:"user,omitempty" json:"user,omitempty"`
 
 	// Limit number of elements to return
 	Limit *Limit `form:"limit,omitempty" json:"limit,omitempty"`
 }
 
+// GetRunsParams defines parameters for GetRuns.
+type GetRunsParams struct {
+	// ActionId return only runs of the action
+	ActionId *string `form:"actionId,omitempty" json:"actionId,omitempty"`
+
+	// Status return only runs with one of the statuses
+	Status *[]ActionRunStatus `form:"status,omitempty" json:"status,omitempty"`
+
+	// From return only runs started at or after the time
+	From *time.Time `form:"from,omitempty" json:"from,omitempty"`
+
+	// To return only runs started before the time
+	To *time.Time `form:"to,omitempty" json:"to,omitempty"`
+
+	// Q return only runs containing the text in run id, action id or error
+	Q *string `form:"q,omitempty" json:"q,omitempty"`
+
+	// Sort sort order by run start time
+	Sort *GetRunsParamsSort `form:"sort,omitempty" json:"sort,omitempty"`
+
+	// Cursor cursor returned in the previous page to fetch the next one
+	Cursor *string `form:"cursor,omitempty" json:"cursor,omitempty"`
+
+	// Limit number of elements to return
+	Limit *Limit `form:"limit,omitempty" json:"limit,omitempty"`
+}
+
+// GetRunsParamsSort defines parameters for GetRuns.
+type GetRunsParamsSort string
+
 // RunActionJSONRequestBody defines body for RunAction for application/json ContentType.
 type RunActionJSONRequestBody = ActionRunParams
 
+// ValidateActionInputJSONRequestBody defines body for ValidateActionInput for application/json ContentType.
+type ValidateActionInputJSONRequestBody = ActionRunParams
+
 // ServerInterface represents all server handlers.
 type ServerInterface interface {
 	// Lists all actions
@@ -167,15 +320,33 @@ type ServerInterface interface {
 	// Cancels running action
 	// (POST /actions/{id}/running/{runId}/cancel)
 	CancelRunningAction(w http.ResponseWriter, r *http.Request, id ActionId, runId ActionRunInfoId)
+	// Streams action run events
+	// (GET /actions/{id}/running/{runId}/events)
+	GetRunningActionEvents(w http.ResponseWriter, r *http.Request, id ActionId, runId ActionRunInfoId, params GetRunningActionEventsParams)
 	// Returns running action streams
 	// (GET /actions/{id}/running/{runId}/streams)
 	GetRunningActionStreams(w http.ResponseWriter, r *http.Request, id ActionId, runId ActionRunInfoId, params GetRunningActionStreamsParams)
 	// Returns action json schema
 	// (GET /actions/{id}/schema.json)
 	GetActionJSONSchema(w http.ResponseWriter, r *http.Request, id ActionId)
+	// Validates action input
+	// (POST /actions/{id}/validate)
+	ValidateActionInput(w http.ResponseWriter, r *http.Request, id ActionId)
+	// Lists audit log records
+	// (GET /audit)
+	GetAuditLog(w http.ResponseWriter, r *http.Request, params GetAuditLogParams)
 	// Customisation config
 	// (GET /customisation)
 	GetCustomisationConfig(w http.ResponseWriter, r *http.Request)
+	// Lists action runs history
+	// (GET /runs)
+	GetRuns(w http.ResponseWriter, r *http.Request, params GetRunsParams)
+	// Returns action run from history
+	// (GET /runs/{runId})
+	GetRunByID(w http.ResponseWriter, r *http.Request, runId ActionRunInfoId)
+	// Returns server status
+	// (GET /status)
+	GetServerStatus(w http.ResponseWriter, r *http.Request)
 	// Lists all wizards
 	// (GET /wizard)
 	GetWizards(w http.ResponseWriter, r *http.Request)
@@ -224,6 +395,12 @@ func (_ Unimplemented) CancelRunningAction(w http.ResponseWriter, r *http.Reques
 	w.WriteHeader(http.StatusNotImplemented)
 }
 
+// Streams action run events
+// (GET /actions/{id}/running/{runId}/events)
+func (_ Unimplemented) GetRunningActionEvents(w http.ResponseWriter, r *http.Request, id ActionId, runId ActionRunInfoId, params GetRunningActionEventsParams) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
 // Returns running action streams
 // (GET /actions/{id}/running/{runId}/streams)
 func (_ Unimplemented) GetRunningActionStreams(w http.ResponseWriter, r *http.Request, id ActionId, runId ActionRunInfoId, params GetRunningActionStreamsParams) {
@@ -236,12 +413,42 @@ func (_ Unimplemented) GetActionJSONSchema(w http.ResponseWriter, r *http.Reques
 	w.WriteHeader(http.StatusNotImplemented)
 }
 
+// Validates action input
+// (POST /actions/{id}/validate)
+func (_ Unimplemented) ValidateActionInput(w http.ResponseWriter, r *http.Request, id ActionId) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// Lists audit log records
+// (GET /audit)
+func (_ Unimplemented) GetAuditLog(w http.ResponseWriter, r *http.Request, params GetAuditLogParams) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
 // Customisation config
 // (GET /customisation)
 func (_ Unimplemented) GetCustomisationConfig(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusNotImplemented)
 }
 
+// Lists action runs history
+// (GET /runs)
+func (_ Unimplemented) GetRuns(w http.ResponseWriter, r *http.Request, params GetRunsParams) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// Returns action run from history
+// (GET /runs/{runId})
+func (_ Unimplemented) GetRunByID(w http.ResponseWriter, r *http.Request, runId ActionRunInfoId) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// Returns server status
+// (GET /status)
+func (_ Unimplemented) GetServerStatus(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
 // Lists all wizards
 // (GET /wizard)
 func (_ Unimplemented) GetWizards(w http.ResponseWriter, r *http.Request) {
@@ -420,6 +627,64 @@ func (siw *ServerInterfaceWrapper) CancelRunningAction(w http.ResponseWriter, r
 	handler.ServeHTTP(w, r)
 }
 
+// GetRunningActionEvents operation middleware
+func (siw *ServerInterfaceWrapper) GetRunningActionEvents(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id ActionId
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", chi.URLParam(r, "id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "id", Err: err})
+		return
+	}
+
+	// ------------- Path parameter "runId" -------------
+	var runId ActionRunInfoId
+
+	err = runtime.BindStyledParameterWithOptions("simple", "runId", chi.URLParam(r, "runId"), &runId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "runId", Err: err})
+		return
+	}
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetRunningActionEventsParams
+
+	headers := r.Header
+
+	// ------------- Optional header parameter "Last-Event-ID" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("Last-Event-ID")]; found {
+		var LastEventID string
+		n := len(valueList)
+		if n != 1 {
+			siw.ErrorHandlerFunc(w, r, &TooManyValuesForParamError{ParamName: "Last-Event-ID", Count: n})
+			return
+		}
+
+		err = runtime.BindStyledParameterWithOptions("simple", "Last-Event-ID", valueList[0], &LastEventID, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationHeader, Explode: false, Required: false})
+		if err != nil {
+			siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "Last-Event-ID", Err: err})
+			return
+		}
+
+		params.LastEventID = &LastEventID
+
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetRunningActionEvents(w, r, id, runId, params)
+	}))
+
+	for i := len(siw.HandlerMiddlewares) - 1; i >= 0; i-- {
+		handler = siw.HandlerMiddlewares[i](handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
 // GetRunningActionStreams operation middleware
 func (siw *ServerInterfaceWrapper) GetRunningActionStreams(w http.ResponseWriter, r *http.Request) {
 
@@ -446,6 +711,14 @@ func (siw *ServerInterfaceWrapper) GetRunningActionStreams(w http.ResponseWriter
 	// Parameter object where we will unmarshal all parameters from the context
 	var params GetRunningActionStreamsParams
 
+	// ------------- Optional query parameter "type" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "type", r.URL.Query(), &params.Type)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "type", Err: err})
+		return
+	}
+
 	// ------------- Optional query parameter "offset" -------------
 
 	err = runtime.BindQueryParameter("form", true, false, "offset", r.URL.Query(), &params.Offset)
@@ -498,6 +771,74 @@ func (siw *ServerInterfaceWrapper) GetActionJSONSchema(w http.ResponseWriter, r
 	handler.ServeHTTP(w, r)
 }
 
+// ValidateActionInput operation middleware
+func (siw *ServerInterfaceWrapper) ValidateActionInput(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id ActionId
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", chi.URLParam(r, "id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "id", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.ValidateActionInput(w, r, id)
+	}))
+
+	for i := len(siw.HandlerMiddlewares) - 1; i >= 0; i-- {
+		handler = siw.HandlerMiddlewares[i](handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// GetAuditLog operation middleware
+func (siw *ServerInterfaceWrapper) GetAuditLog(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetAuditLogParams
+
+	// ------------- Optional query parameter "actionId" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "actionId", r.URL.Query(), &params.ActionId)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "actionId", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "user" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "user", r.URL.Query(), &params.User)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "user", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "limit" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "limit", r.URL.Query(), &params.Limit)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "limit", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetAuditLog(w, r, params)
+	}))
+
+	for i := len(siw.HandlerMiddlewares) - 1; i >= 0; i-- {
+		handler = siw.HandlerMiddlewares[i](handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
 // GetCustomisationConfig operation middleware
 func (siw *ServerInterfaceWrapper) GetCustomisationConfig(w http.ResponseWriter, r *http.Request) {
 
@@ -512,6 +853,128 @@ func (siw *ServerInterfaceWrapper) GetCustomisationConfig(w http.ResponseWriter,
 	handler.ServeHTTP(w, r)
 }
 
+// GetRuns operation middleware
+func (siw *ServerInterfaceWrapper) GetRuns(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetRunsParams
+
+	// ------------- Optional query parameter "actionId" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "actionId", r.URL.Query(), &params.ActionId)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "actionId", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "status" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "status", r.URL.Query(), &params.Status)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "status", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "from" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "from", r.URL.Query(), &params.From)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "from", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "to" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "to", r.URL.Query(), &params.To)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "to", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "q" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "q", r.URL.Query(), &params.Q)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "q", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "sort" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "sort", r.URL.Query(), &params.Sort)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "sort", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "cursor" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "cursor", r.URL.Query(), &params.Cursor)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "cursor", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "limit" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "limit", r.URL.Query(), &params.Limit)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "limit", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetRuns(w, r, params)
+	}))
+
+	for i := len(siw.HandlerMiddlewares) - 1; i >= 0; i-- {
+		handler = siw.HandlerMiddlewares[i](handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// GetRunByID operation middleware
+func (siw *ServerInterfaceWrapper) GetRunByID(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// ------------- Path parameter "runId" -------------
+	var runId ActionRunInfoId
+
+	err = runtime.BindStyledParameterWithOptions("simple", "runId", chi.URLParam(r, "runId"), &runId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "runId", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetRunByID(w, r, runId)
+	}))
+
+	for i := len(siw.HandlerMiddlewares) - 1; i >= 0; i-- {
+		handler = siw.HandlerMiddlewares[i](handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// GetServerStatus operation middleware
+func (siw *ServerInterfaceWrapper) GetServerStatus(w http.ResponseWriter, r *http.Request) {
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetServerStatus(w, r)
+	}))
+
+	for i := len(siw.HandlerMiddlewares) - 1; i >= 0; i-- {
+		handler = siw.HandlerMiddlewares[i](handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
 // GetWizards operation middleware
 func (siw *ServerInterfaceWrapper) GetWizards(w http.ResponseWriter, r *http.Request) {
 
@@ -682,15 +1145,33 @@ func HandlerWithOptions(si ServerInterface, options ChiServerOptions) http.Handl
 	r.Group(func(r chi.Router) {
 		r.Post(options.BaseURL+"/actions/{id}/running/{runId}/cancel", wrapper.CancelRunningAction)
 	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/actions/{id}/running/{runId}/events", wrapper.GetRunningActionEvents)
+	})
 	r.Group(func(r chi.Router) {
 		r.Get(options.BaseURL+"/actions/{id}/running/{runId}/streams", wrapper.GetRunningActionStreams)
 	})
 	r.Group(func(r chi.Router) {
 		r.Get(options.BaseURL+"/actions/{id}/schema.json", wrapper.GetActionJSONSchema)
 	})
+	r.Group(func(r chi.Router) {
+		r.Post(options.BaseURL+"/actions/{id}/validate", wrapper.ValidateActionInput)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/audit", wrapper.GetAuditLog)
+	})
 	r.Group(func(r chi.Router) {
 		r.Get(options.BaseURL+"/customisation", wrapper.GetCustomisationConfig)
 	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/runs", wrapper.GetRuns)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/runs/{runId}", wrapper.GetRunByID)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/status", wrapper.GetServerStatus)
+	})
 	r.Group(func(r chi.Router) {
 		r.Get(options.BaseURL+"/wizard", wrapper.GetWizards)
 	})
@@ -704,31 +1185,54 @@ func HandlerWithOptions(si ServerInterface, options ChiServerOptions) http.Handl
 // Base64 encoded, gzipped, json marshaled Swagger object
 var swaggerSpec = []string{
 
-	"H4sIAAAAAAAC/8xZS3PbNhD+Kxy0R1qU05tuju121MnEGWs6Pbg+wOBSREKCDB61VQ3/ewcPPiCSEhVL",
-	"dk62iCV299v3cotIkZcFAyYFWmxRiTnOQQI3v66IpAVbxvr/GAThtNQP0AItb4IiCbA5D2QRJCBJikJE",
-	"9WGJpf6f4RzQAtEYhYjDd0U5xGghuYIQCZJCjvW9clNqKiE5ZWtUVaHjeq/YkiXFOHOZQsAVY5StnSDD",
-	"/LnSGhwnwieaU9lnzFT+BFwzhwxyjZnWnYNUvGH+XQHftNwzc1OXW45faK5ytLicz0OUU+Z+hbUclElY",
-	"AzeC3CWJgMmSiG+0HJGjsBcNqN1l9zf9D/N4HPNnc35ag1eaWJQFE2Cc7gYSrDJ5y3nB9W9SMAnMYIDL",
-	"MqMEa5Gir0LLte1c/CuHBC3QL1Hr0pE9FZG9zTDz9VIMXkogEuIAHE0tbCcEfldZZgTIsrsELR72M7Pv",
-	"rNKCS1SFW1TyogQuqdVPCz5N6D9Xd59XlrIKkaI9DIunr0C0UV8u1sWFg/+vpXvHPXa0OS4fLOaP2uI8",
-	"wQS2VZfoQrvPRWGQwdlFWRg6a0BrpdqkD10lHsMdcarH3SD2ofPhoHHfKXyFljdafSGxVOIQZA3blSXf",
-	"ldv4prvqgOBfdCoU+0THfK3yOnMOmsQ9tOlptmSlku7aLu40L7WrmPQrU7RAaypT9TQjRR5lWDGSciKz",
-	"+t+o/LaOXMLT6pEUszUYFKmEXAzEWKMo5hxv9G9r5fcVvAQuqKhj+93E4IpJqh3t3WTY8dHWrVo7tWJ6",
-	"uB1w4VUTM8B0jXlAhAOWENv7mI21hDIqUvPQpsAQEcwIZBB3GLS+1LmeA85vsMT7oqSTv3tXkUJ5J00t",
-	"CuuCNXhmn7RaCRnfKVPcZLxk9u8t5wPS70BtTsNGxLAtk1ayffja/L5Hca/QDCg/NfNJKjMY7lN6qc3S",
-	"+kVuWIlrJWSRU4F35BusKR7xdcESuj5teQlRU+93vSc2uicFz7G0boDCAZ/IQQi8noCTubGl76MTok7h",
-	"PQBMh9LHoy2Qs/75j2SLTsVtO7Xj+hL7zlhfIiSUwqsiE+6SUPbLyw7g9uJhL+yK9AahJBQhIEZK5CvD",
-	"rL19r6oasZ6X21IwHf1OVzpQ3A+hNaqpKUXUNWx+n3zFgqsvSz0DfLJOGdQyhyijBJgwNzq0r0pMUgg+",
-	"zOYoRIpnaIFSKUuxiKLn5+cZNsezgq8j966IPi2vbz+vbi8+zOazVOZZR1DkWKIQ/atrnxHocjafzW0r",
-	"AwyXFC3Qb7NLw1DHlIEw6uC6Hhqj7s3oJgKcZUHm6/UPM+UXOK5nYPQHyKtGaW9m+TCfHzWqHGHkOlx3",
-	"Y6w3yTi5g1ow6wlmlhrj1OgQeUOXGYFUnmO+0eBTIS1CNZr6vIY22tK4GsWX1/jaRcHTJjDhMwLrx83y",
-	"xpiv3UGMZLSWJGp2FNXjK40yNeDGoD858vdD6OnGuRADWMMLECVBtMsQH+Z7xa7qk1dB/F2BkB+LeHNi",
-	"dNuZawBiSxI0vXGAWRx0umNv3VD1POHy9LKawfbtnIGrxhP6ARjV3fyhQPQXZmIoFu8tict07xyTRyTK",
-	"xiRTU6UGI6DulVPG6y7Io+aKtmY5OTl/NgIPmO2OgWe5VxounEjbrmnfIP9OiLozmbR3/SGTRnaENq3e",
-	"YLq+Nueiv8L2LWvJPMv+FFb1lVnZzjdRWdCIfyoTjAB12ALCrCfEkTkxqF87lBpXDd0bWuPwK+6LwQRK",
-	"+5HjjVN0Z2d0fKJubHPWhN1y6bmYm+hrEKbkbE0biHoFMNL5emuEn7H/7X6KGDVTV9Xz5F+Pg7YO2d1i",
-	"OZP0cB7eYJ3f8f012wSXJ0a2k7ePnhyBZWIhtN/0Jo3IucokFRLK/UOy3XS8zZDs77QOomuVPeeQ7Dh0",
-	"sZ02I7tvq6MzstX0h9q65rPuWXNEZyU5ivzZZmQPvaqqqv8DAAD//0xVtFjaIAAA",
+	"H4sIAAAAAAAC/+RbS3PjuBH+KygkVTmEljyTPenmHXsTpVw7W9YmOYx9gMmWhB0S4OAhW3Hpv6fw4AMk",
+	"KFIzsmeqcpNEEN399QPdjdYLTnlRcgZMSbx4wSURpAAFwn67ShXlbJmZzxnIVNDS/IAXeHmN+BoR+xwp",
+	"jtag0i1OMDUPS6LMZ0YKwAtMM5xgAV80FZDhhRIaEizTLRTE7Kv2pVkllaBsgw+HxFO902zJ1nyYuNoC",
+	"EpoxyjaekTh9odnyVBZuaUFVnzDTxSMIQxxyKAxmRnYBSoua+BcNYt9Qz+1ObWoFeaaFLvDi3eVlggvK",
+	"/Lek4oMyBRsQlpGP67WEyZzIz7Qc4IO7jSJit8mtlABSDEjvGUcN7ce9ghYEidXJ05bngARIVWlJ2l0R",
+	"lX4dZIiuES+oUpBNx+04Uo71cbxqng1YaC14YXl8hA11thQwPR3Nmr3LI+z9bh90mTPLO1i1MJUq+6gV",
+	"4sJ8uhEiQSTP/TqJiIATULWMHTf9/9D/EpENu92TfX5enz+YxbLkTIKNO9ewJjpXN0JwYb6nnClgVq2k",
+	"LHOaEsPS/A9p+HppbfxnAWu8wH+aN1Ft7p7KudvNEgvl0gyeS0gVZAjcmgQv2Y7kNFuyUp+ZDbvlv83m",
+	"9u1BrnxspWa5cR3qOLI68ns1IfoXneeWrTz/uMaLT8dZcO+stlwofEhecCl4CUJRB74RZ5oo/1x9/HXl",
+	"Vh4SrGlPwfzxD0gVTvDzxYZfeNv419K/43/2awtSfnIG8WD8RqxJCi+H9qIL47EX3AJE8ouS23XOug6H",
+	"tr19agvxkHTYOTx0D5kQuhAOmvUtNhRoeW3El4ooLccgq8mu3PIu39Zx/FYjjN/yjTuyA3ZdjIg4WYJd",
+	"IIl7fZsHvy6p9urz0eLiN5MwyGMAErHRRZVfRA3D/+gsfmYdxG/b1j4tSi6US1LUFi/whqqtfpylvJjn",
+	"RLN0K1KVVx/n5efN3O1oBUy3hG3A6pIqKGQUoYoRIcjefHe29n0ZL0FIKqu4893YEJopWsB35KFjpY1Z",
+	"NXpq2AxwG3GkO0i5yE4Nn1Xo6AdQ0sqaj8UNn13b6AHVGdOzyTVlVG4hu7KwrbkoiMILnBEFF17U3ju5",
+	"Dw2TpLBxxBha7cmTXvPadZFPqFMY7Gqywqvmob2nl2aSDm+pVP2QWPt7/WGSgG7LWFhg8Kw+aCGdxsJj",
+	"O7W/1+mRzenMelSSDSSIPEpgCnFmH+REugejGDnWj0biVX0AATN56CecCiAuF/R1Em7MCVc2l+CUsBRy",
+	"yPBDj4tge5NwXhNFjgX7VorU2yrlmk1IzanDxu+UIJdro78i+75Jhcxj/6vNk79okKpBOt1q9hn3s/Ck",
+	"Stt7LBjC1Y583SZfcVNXA/1Nlc/pK9Tbx+eSxY7RAR375LyCMGmqDIfcMRdwydwRxQQCR5QzNc1RVOUw",
+	"nkPYPMatDRPbASF0RlUTh88QT1lm88O+rv/x+++/oQLUlmeIsAyZg6hSujelWEj96ugooOAKrrIsEixI",
+	"lgmQsra5nAKLUhd6gvx32gvfpKIhOfc7SnkGjcSu7Ipbtj/wpx06WkJMRK22wJSplSBDds2oHzgCLeBa",
+	"+jySHSf4g5aKF1SSjplH65Bg8QfO1nRz3pIkwXXl2A2SWYgrbWu9pYACpDSnw6i72R2b9TFsbEr2C4U8",
+	"G+DqM+yfvP+FKjRlHnIWjvwiazs7kmtAO8pzokAm6B5r9pnxJ3aPTSfCLPFFrADDhQvcrqBdc4EI42oL",
+	"Agkg0jbveiY1LH+Ca6yj7Pqn5nxwRG3x7FhOEMw2MzT3WePcpqRjRlmRS2qcJsDdLfMnp5g3vg/R1ZE9",
+	"sqfnMl2d93KZjpB++3iMblX7I57VWhk6VFOVz/rPv6Y4aDZ0TTYQOxBNIhSCV9KIcZeCpyYE06zuwNlN",
+	"ov7o0ygX5eWxVCbVQgBT+b7TopbRfU/OoBO8AyF9kAt58A8qaUhZjtp2tVdiIQqT747IMVN3HcPTWlDu",
+	"naEWlFRQTrdyv5eCctTA3cZx+26z9AaJlNSpMbzoHt+aZDW7HxXVIDaQbZ1aL1ntR0qlMbQGJbX1PvW9",
+	"uWAXfMXQ1W9LY+C3LhS0fCunKTBpd6yywpKkW0DvZ5c4wVrkeIG3SpVyMZ8/PT3NiH0842Iz9+/K+e3y",
+	"w82vq5uL97PL2VYVeYtR7Enilgfid7PL2aXrFwEjJcUL/LfZO0vQRDIL4byF6yZWg9zZJr60zf08lOue",
+	"2R4HCFIlwvjvoK5qoYPe+fvLy5N61ScouXLXro8N9K5lk13aFbanP0SplmEeNP8P1lOKgoi9AZ9K5RCq",
+	"0DTPK2jnLzQ7DOIrKnztYvS4RzQbhvXn/fIaJ8F16EBEa5bM6+vSw8M3KmWqww1eG5wb+bsYejYLkxGs",
+	"4RlSrUA297IhzHeaXVVPvgliW679zLP9mdFtVXB9iN0SVDcgbRnZakEG116HniW8Oz+vrhE5yRh+ev9+",
+	"3BD6t19nMiOhaxvqu+686lKNuXA/pep58V2Qtnxnbz6t7+i7yhODrAEDUf/KOT29C/KguuYvtjsxOfLW",
+	"DEfU9pFBoLlvVFwycW0za/IGkXuCv76SSnvbj6l07lrDhng80H+wz2V/DifUrFsWaPaH0GoozMrlzGud",
+	"o5r9c6lgAKhxDcCuujqN+tbKj4H4YShEJHJF8MUKmEI39u0Fusdcq1Kre4yeqNqiSFfftc1lcs/ufYut",
+	"t9igiThDVR/RXqna08+QX1NGcnRf3zD0Xnf93dk9W4F/pSm67U2IgBToDjIEO996vyVSXVghLpbXaAsk",
+	"cz0dAVIX0OrMz+I5cmByDoy3Nbykq7DjIsdEQ2StQCCqqlEbB0MzbBOAdHS6Zzy4KXhWzuYuHPUwukUG",
+	"dwYjmLNcL8S5/Kiy9x6dCa7kOJEnpheoem3MvFb1ure1r5FXWoNnk1f7KbrJ693A4BvnSU3o+opsqdbq",
+	"q2ZNDZWecfo2aAXClMTJrPV9+OHCNei9/ojla4vBYTW1RX2dJCig0NPOzjXuYTjz8a39usZtysGkqgUT",
+	"5KdR3D1jPY+C1jnZuCNbkgLQE9mbc9tZZ3LPHrVCGQfJ/qLML62LlMghV/HhlWcqtv/Hovqnvoa6A5R+",
+	"fPJ7l8A9w7EMehs01+Dj55PNo+pbY9MLLbQi3uYK7tMKY2hUSJUgsiM0J485mPyCZAVlxtryfTSKGBZu",
+	"+aZvRDFm7DZdhsJh/M4EcmvY58gU8gnU/JVyjJZ/dJTOiGu86cnWGoKYcqKZ5SjnmwqRM3dZ+9sbE027",
+	"V+zeVHuGFL9ef30UA7pTcEwtb2fvmAZ8IEfEQSj0hGsAqbiArJXnWoNvtb7dny163m5as579wZJInuTc",
+	"nvRbeLYhZWtGzlp/iDDFJhie4bnM7eiE+z9BjAm3GienWk1vLrt/lSXV3t4AmftZPEEQf4uKiP3vhivi",
+	"jED+RjfG/VrwIuB92jTlZFYeYc0FjHGh+GvwYLycUJscW/pmUo/6hDxL6sMwM2BVk4kx7r6cZl+SC4O/",
+	"6Rs8Wk4cGMfkl24epCFShwO7N07qUT9iv9kfHyZg4mdDm7/ruNyuFLCjXEs7CRqZHOVsiFO34Y9xxJ0w",
+	"U2sOmOM1mkRbKhUX+zOfaTECVUj+mj62/ftYtVM82H5DC/ut29JV7nG0ehZ1gvJKrekAU6udZp7xqF78",
+	"hXzS8nBbReSc9Id7eqoKZodeEe2ATgRqx6E/9s4Nc2dzA677P9+ksYRC54pKBeXxwQQ3XfI2gwnhHNFo",
+	"rueEfc3BBE+hje20uQS3dnguwUn6VdGk/kvnq4aR1hjYIPKvNpcQoGeGh/43AONY+AHZPgAA",
 }
 
 // GetSwagger returns the content of the embedded swagger specification file
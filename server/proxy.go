@@ -0,0 +1,219 @@
+package server
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"io"
+	"io/fs"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// publicURL builds the external URLs of the server deployed behind a reverse proxy.
+type publicURL struct {
+	// fixed is an URL set by the user, the request is ignored if set.
+	fixed *url.URL
+	// basePath is a path prefix of all routes on the server.
+	basePath string
+	// trusted are the addresses of the proxies allowed to set X-Forwarded-* headers.
+	trusted []netip.Prefix
+}
+
+func newPublicURL(rawURL, basePath string, trusted []netip.Prefix) (*publicURL, error) {
+	p := &publicURL{basePath: strings.TrimSuffix(basePath, "/"), trusted: trusted}
+	if rawURL == "" {
+		return p, nil
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("invalid public url %q: absolute url expected", rawURL)
+	}
+	u.Path = strings.TrimSuffix(u.Path, "/")
+	u.RawQuery, u.Fragment = "", ""
+	p.fixed = u
+	return p, nil
+}
+
+// ParseTrustedProxies parses the addresses and networks of the trusted proxies,
+// e.g. "10.0.0.1" or "10.0.0.0/8".
+func ParseTrustedProxies(values []string) ([]netip.Prefix, error) {
+	prefixes := make([]netip.Prefix, 0, len(values))
+	for _, v := range values {
+		v = strings.TrimSpace(v)
+		if v == "" {
+			continue
+		}
+		if strings.Contains(v, "/") {
+			prefix, err := netip.ParsePrefix(v)
+			if err != nil {
+				return nil, fmt.Errorf("invalid trusted proxy %q: %w", v, err)
+			}
+			prefixes = append(prefixes, prefix.Masked())
+			continue
+		}
+		addr, err := netip.ParseAddr(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: %w", v, err)
+		}
+		addr = addr.Unmap()
+		prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+	}
+	return prefixes, nil
+}
+
+// isTrusted checks if the address belongs to a trusted proxy.
+func (p *publicURL) isTrusted(addr string) bool {
+	ip, err := netip.ParseAddr(addr)
+	if err != nil {
+		return false
+	}
+	ip = ip.Unmap()
+	return slices.ContainsFunc(p.trusted, func(prefix netip.Prefix) bool {
+		return prefix.Contains(ip)
+	})
+}
+
+// isProxied checks if the request comes from a trusted proxy.
+// Only the owner may connect to the unix socket, it's a proxy set up by the user.
+func (p *publicURL) isProxied(r *http.Request) bool {
+	if addr, ok := r.Context().Value(http.LocalAddrContextKey).(net.Addr); ok && addr.Network() == NetworkUnix {
+		return true
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return false
+	}
+	return p.isTrusted(host)
+}
+
+// forwardedValue returns the first value of the X-Forwarded-* header set by the nearest proxy.
+// The headers of the requests not coming from a trusted proxy are ignored, clients may forge them.
+func (p *publicURL) forwardedValue(r *http.Request, header string) string {
+	if !p.isProxied(r) {
+		return ""
+	}
+	v, _, _ := strings.Cut(r.Header.Get(header), ",")
+	return strings.TrimSpace(v)
+}
+
+// scheme returns the scheme of the request seen by the client.
+func (p *publicURL) scheme(r *http.Request) string {
+	if p.fixed != nil {
+		return p.fixed.Scheme
+	}
+	if proto := p.forwardedValue(r, "X-Forwarded-Proto"); proto == "http" || proto == "https" {
+		return proto
+	}
+	if r.TLS != nil {
+		return "https"
+	}
+	return "http"
+}
+
+// host returns the host of the request seen by the client.
+func (p *publicURL) host(r *http.Request) string {
+	if p.fixed != nil {
+		return p.fixed.Host
+	}
+	if host := p.forwardedValue(r, "X-Forwarded-Host"); host != "" {
+		return host
+	}
+	return r.Host
+}
+
+// path returns the path prefix of the routes seen by the client, without a trailing slash.
+// A proxy removing its prefix from the path reports it in X-Forwarded-Prefix header.
+func (p *publicURL) path(r *http.Request) string {
+	if p.fixed != nil {
+		return p.fixed.Path
+	}
+	prefix := strings.TrimSuffix(p.forwardedValue(r, "X-Forwarded-Prefix"), "/")
+	if prefix != "" && !strings.HasPrefix(prefix, "/") {
+		prefix = "/" + prefix
+	}
+	return prefix + p.basePath
+}
+
+// clientAddr returns the address of the client, the trusted proxy reports it in X-Forwarded-For header.
+// Every proxy appends the address of its peer, the first untrusted one from the end is the client.
+func (p *publicURL) clientAddr(r *http.Request) string {
+	if !p.isProxied(r) {
+		return r.RemoteAddr
+	}
+	addrs := strings.Split(strings.Join(r.Header.Values("X-Forwarded-For"), ","), ",")
+	client := ""
+	for _, addr := range slices.Backward(addrs) {
+		client = strings.TrimSpace(addr)
+		if !p.isTrusted(client) {
+			break
+		}
+	}
+	if client == "" {
+		return r.RemoteAddr
+	}
+	return client
+}
+
+// root returns the absolute URL of the server root, without a trailing slash.
+func (p *publicURL) root(r *http.Request) string {
+	return p.scheme(r) + "://" + p.host(r) + p.path(r)
+}
+
+// stripBasePath serves the routes under the base path. Requests outside of it aren't found.
+func stripBasePath(basePath string, next http.Handler) http.Handler {
+	basePath = strings.TrimSuffix(basePath, "/")
+	if basePath == "" {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p := strings.TrimPrefix(r.URL.Path, basePath)
+		if len(p) == len(r.URL.Path) || p != "" && p[0] != '/' {
+			http.NotFound(w, r)
+			return
+		}
+		if p == "" {
+			p = "/"
+		}
+		r2 := r.Clone(r.Context())
+		r2.URL.Path = p
+		r2.URL.RawPath = ""
+		next.ServeHTTP(w, r2)
+	})
+}
+
+var baseHrefRegexp = regexp.MustCompile(`<base\s+href="[^"]*"\s*/?>`)
+
+// serveIndex serves index.html of the client with the base href pointing to the public path.
+// The client resolves its routes and API relative to the base.
+func serveIndex(w http.ResponseWriter, r *http.Request, clientFS fs.FS, basePath string) {
+	f, err := clientFS.Open("index.html")
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	defer f.Close()
+	data, err := io.ReadAll(f)
+	if err != nil {
+		sendError(w, http.StatusInternalServerError, "Error reading index.html")
+		return
+	}
+
+	base := []byte(`<base href="` + html.EscapeString(basePath+"/") + `" />`)
+	if loc := baseHrefRegexp.FindIndex(data); loc != nil {
+		data = slices.Concat(data[:loc[0]], base, data[loc[1]:])
+	} else if i := bytes.Index(data, []byte("<head>")); i != -1 {
+		i += len("<head>")
+		data = slices.Concat(data[:i], base, data[i:])
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(data)
+}
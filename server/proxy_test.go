@@ -0,0 +1,154 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"slices"
+	"testing"
+)
+
+func TestParseTrustedProxies(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		values []string
+		exp    []string
+		expErr bool
+	}{
+		{"address", []string{"10.0.0.1"}, []string{"10.0.0.1/32"}, false},
+		{"network", []string{"10.0.0.0/8"}, []string{"10.0.0.0/8"}, false},
+		{"network is masked", []string{"10.1.2.3/8"}, []string{"10.0.0.0/8"}, false},
+		{"ipv6 address", []string{"::1"}, []string{"::1/128"}, false},
+		{"ipv4-mapped address", []string{"::ffff:10.0.0.1"}, []string{"10.0.0.1/32"}, false},
+		{"spaces and empty values", []string{" 10.0.0.1 ", ""}, []string{"10.0.0.1/32"}, false},
+		{"invalid address", []string{"proxy.local"}, nil, true},
+		{"invalid network", []string{"10.0.0.0/33"}, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			prefixes, err := ParseTrustedProxies(tt.values)
+			if (err != nil) != tt.expErr {
+				t.Fatalf("expected error %v, got %v", tt.expErr, err)
+			}
+			if tt.expErr {
+				return
+			}
+			got := make([]string, 0, len(prefixes))
+			for _, p := range prefixes {
+				got = append(got, p.String())
+			}
+			if !slices.Equal(got, tt.exp) {
+				t.Fatalf("expected %v, got %v", tt.exp, got)
+			}
+		})
+	}
+}
+
+func TestPublicURL(t *testing.T) {
+	t.Parallel()
+
+	trusted := []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")}
+	proxy := "10.0.0.1:1234"
+	client := "192.0.2.1:1234"
+	tests := []struct {
+		name       string
+		fixed      string
+		basePath   string
+		remoteAddr string
+		headers    map[string]string
+		expRoot    string
+		expClient  string
+	}{
+		{"direct request", "", "", client, nil, "http://example.com", client},
+		{"base path", "", "/web/", client, nil, "http://example.com/web", client},
+		{
+			"trusted proxy", "", "", proxy,
+			map[string]string{"X-Forwarded-Proto": "https", "X-Forwarded-Host": "public.com", "X-Forwarded-For": "203.0.113.5"},
+			"https://public.com", "203.0.113.5",
+		},
+		{
+			"untrusted client forging headers", "", "", client,
+			map[string]string{"X-Forwarded-Proto": "https", "X-Forwarded-Host": "public.com", "X-Forwarded-For": "203.0.113.5"},
+			"http://example.com", client,
+		},
+		{
+			"proxy prefix with base path", "", "/web", proxy,
+			map[string]string{"X-Forwarded-Prefix": "apps/"},
+			"http://example.com/apps/web", proxy,
+		},
+		{
+			"first value of the nearest proxy", "", "", proxy,
+			map[string]string{"X-Forwarded-Proto": "https, http", "X-Forwarded-Host": "public.com, internal.local"},
+			"https://public.com", proxy,
+		},
+		{"unknown forwarded scheme", "", "", proxy, map[string]string{"X-Forwarded-Proto": "ftp"}, "http://example.com", proxy},
+		{
+			"chain of trusted proxies", "", "", proxy,
+			map[string]string{"X-Forwarded-For": "198.51.100.1, 203.0.113.5, 10.0.0.2"},
+			"http://example.com", "203.0.113.5",
+		},
+		{"empty forwarded for", "", "", proxy, map[string]string{"X-Forwarded-For": ""}, "http://example.com", proxy},
+		{
+			"fixed url", "https://public.com/web/", "/web", proxy,
+			map[string]string{"X-Forwarded-Host": "other.com"},
+			"https://public.com/web", proxy,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			p, err := newPublicURL(tt.fixed, tt.basePath, trusted)
+			if err != nil {
+				t.Fatal(err)
+			}
+			r := httptest.NewRequest(http.MethodGet, "http://example.com/", nil)
+			r.RemoteAddr = tt.remoteAddr
+			for k, v := range tt.headers {
+				r.Header.Set(k, v)
+			}
+			if got := p.root(r); got != tt.expRoot {
+				t.Fatalf("expected root %q, got %q", tt.expRoot, got)
+			}
+			if got := p.clientAddr(r); got != tt.expClient {
+				t.Fatalf("expected client %q, got %q", tt.expClient, got)
+			}
+		})
+	}
+}
+
+func TestStripBasePath(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		basePath string
+		path     string
+		expCode  int
+		expPath  string
+	}{
+		{"no base path", "", "/api/actions", http.StatusOK, "/api/actions"},
+		{"under the base path", "/web", "/web/api/actions", http.StatusOK, "/api/actions"},
+		{"base path with trailing slash", "/web/", "/web/api/actions", http.StatusOK, "/api/actions"},
+		{"base path root", "/web", "/web", http.StatusOK, "/"},
+		{"base path root with slash", "/web", "/web/", http.StatusOK, "/"},
+		{"outside of the base path", "/web", "/api/actions", http.StatusNotFound, ""},
+		{"path sharing the prefix", "/web", "/website", http.StatusNotFound, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var gotPath string
+			h := stripBasePath(tt.basePath, http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+				gotPath = r.URL.Path
+			}))
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+			if w.Code != tt.expCode || gotPath != tt.expPath {
+				t.Fatalf("expected %d %q, got %d %q", tt.expCode, tt.expPath, w.Code, gotPath)
+			}
+		})
+	}
+}
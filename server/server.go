@@ -9,14 +9,18 @@ import (
 	"errors"
 	"fmt"
 	"io/fs"
+	"net"
 	"net/http"
 	"net/http/httputil"
+	"net/netip"
 	"net/url"
 	"os"
 	"os/signal"
 	"path"
+	"path/filepath"
 	"sort"
 	"strings"
+	"sync"
 	"syscall"
 	"time"
 
@@ -37,10 +41,25 @@ type RunOptions struct {
 	action.WithTerm
 
 	// Addr optionally specifies the TCP address in form "host:port" for the server to listen on.
-	// If empty, :80 is used.
+	// If empty, :80 is used. A path to the socket file is expected when Network is "unix".
 	Addr string
+	// Network is a network of the listener, "tcp" or "unix". If empty, "tcp" is used.
+	Network string
+	// Listener is a listener bound with Listen before the server is run.
+	// If nil, the server listens on Addr itself.
+	Listener net.Listener
+	// OnReady is called when the listener is bound and the server starts serving the requests.
+	OnReady func()
 	// APIPrefix specifies subpath where Api is served.
 	APIPrefix string
+	// BasePath is a path prefix of all routes, e.g. "/launchr". The server is served on the root if empty.
+	BasePath string
+	// PublicURL is an absolute URL of the server behind a reverse proxy, e.g. "https://tools.example/launchr".
+	// If empty, the URL is built from the request and X-Forwarded-* headers of the trusted proxies.
+	PublicURL string
+	// TrustedProxies are the addresses of the reverse proxies allowed to set X-Forwarded-* headers.
+	// The requests on the unix socket are always trusted.
+	TrustedProxies []netip.Prefix
 	// SwaggerUIFS enables serving of swagger.json for swagger ui if set.
 	SwaggerUIFS fs.FS
 	// Client server.
@@ -49,15 +68,102 @@ type RunOptions struct {
 	FrontendCustomize FrontendCustomize
 	DefaultUISchema   []byte
 	LogsDirPath       string
+	// HistoryDirPath specifies a directory where the run history is persisted.
+	HistoryDirPath string
+	// AuthTokens enables authentication if set. Only requests with one of the tokens are allowed.
+	AuthTokens []AuthToken
+	// LoginCode is a one-time code logging in with one of the tokens, it expires soon after the start.
+	LoginCode *LoginCode
+	// Access restricts actions available to users.
+	Access AccessConfig
+	// Audit configures the log of the API mutations.
+	Audit AuditOptions
+	// Tracing configures OpenTelemetry tracing of the requests and runs.
+	Tracing TracingOptions
+	// IdleTimeout shuts the server down when it's not used for the duration. Disabled if zero.
+	IdleTimeout time.Duration
+	// Shutdown configures what happens to the running actions when the server is stopped.
+	Shutdown ShutdownOptions
+	// TLSCertFile and TLSKeyFile enable HTTPS if set.
+	TLSCertFile string
+	TLSKeyFile  string
+}
+
+// IsTLS checks if the server is served over HTTPS.
+func (o RunOptions) IsTLS() bool {
+	return o.TLSCertFile != "" && o.TLSKeyFile != ""
 }
 
 // BaseURL returns base url for run options.
 func (o RunOptions) BaseURL() string {
-	return "http://localhost:" + strings.Split(o.Addr, ":")[1]
+	scheme := "http"
+	if o.IsTLS() {
+		scheme = "https"
+	}
+	basePath := strings.TrimSuffix(o.BasePath, "/")
+	if o.Network == NetworkUnix {
+		// The host isn't used to connect, the client dials the socket.
+		return scheme + "://localhost" + basePath
+	}
+	host, port, err := net.SplitHostPort(o.Addr)
+	if err != nil {
+		return scheme + "://localhost" + basePath
+	}
+	// The server listening on all interfaces is reachable locally.
+	if ip := net.ParseIP(host); host == "" || ip != nil && ip.IsUnspecified() {
+		host = "localhost"
+	}
+	return scheme + "://" + net.JoinHostPort(host, port) + basePath
+}
+
+// NetworkUnix is a network of the listener on a unix socket.
+const NetworkUnix = "unix"
+
+// Listen creates a listener on the TCP address or on the unix socket.
+// The port 0 picks a free port, Addr is updated to the bound address so BaseURL reports it.
+func Listen(opts *RunOptions) (net.Listener, error) {
+	if opts.Network != NetworkUnix {
+		addr := opts.Addr
+		if addr == "" {
+			addr = ":http"
+		}
+		ln, err := net.Listen("tcp", addr)
+		if err != nil {
+			return nil, err
+		}
+		opts.Addr = ln.Addr().String()
+		return ln, nil
+	}
+
+	// The socket file is left when the server wasn't stopped properly.
+	if _, err := os.Stat(opts.Addr); err == nil {
+		if conn, err := net.Dial("unix", opts.Addr); err == nil {
+			_ = conn.Close()
+			return nil, fmt.Errorf("socket %s is already in use", opts.Addr)
+		}
+		if err = os.Remove(opts.Addr); err != nil {
+			return nil, fmt.Errorf("can't remove stale socket: %w", err)
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(opts.Addr), 0750); err != nil {
+		return nil, fmt.Errorf("can't create socket dir: %w", err)
+	}
+	ln, err := net.Listen("unix", opts.Addr)
+	if err != nil {
+		return nil, err
+	}
+	// Only the owner may connect to the server.
+	if err = os.Chmod(opts.Addr, 0600); err != nil {
+		_ = ln.Close()
+		return nil, fmt.Errorf("can't set socket permissions: %w", err)
+	}
+	return ln, nil
 }
 
 const (
-	asyncTickerTime = 2
+	// The run switches from created to running when it starts, the switch is checked with a backoff.
+	runStartCheckMin = 10 * time.Millisecond
+	runStartCheckMax = time.Second
 
 	swaggerUIPath   = "/swagger-ui"
 	swaggerJSONPath = "/swagger.json"
@@ -80,10 +186,55 @@ func Run(ctx context.Context, app launchr.App, opts *RunOptions) error {
 		return fmt.Errorf("can't create logs dir")
 	}
 
+	history, err := newRunHistory(opts.HistoryDirPath)
+	if err != nil {
+		return err
+	}
+
+	audit, err := newAuditLog(opts.Audit)
+	if err != nil {
+		return err
+	}
+	defer audit.Close()
+
+	access, err := newAccessPolicy(opts.Access, opts.FrontendCustomize.ExcludedActions, opts.FrontendCustomize.IncludedActions)
+	if err != nil {
+		return err
+	}
+
+	if err = opts.Shutdown.Validate(); err != nil {
+		return err
+	}
+
+	tracing, err := newTracing(ctx, opts.Tracing)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		ctxShut, cancelShut := context.WithTimeout(context.Background(), time.Second*5)
+		defer cancelShut()
+		if err := tracing.shutdown(ctxShut); err != nil {
+			opts.Log().Error("error on flushing traces", "error", err)
+		}
+	}()
+
+	public, err := newPublicURL(opts.PublicURL, opts.BasePath, opts.TrustedProxies)
+	if err != nil {
+		return err
+	}
+
 	ctx, cancel := context.WithCancel(ctx)
 
 	// Prepare router and openapi.
 	r := chi.NewRouter()
+	metrics := newServerMetrics(swagger, opts.APIPrefix)
+	r.Use(metrics.middleware)
+	r.Use(tracing.middleware(opts.APIPrefix, metrics.operation))
+	var idle *idleWatcher
+	if opts.IdleTimeout > 0 {
+		idle = newIdleWatcher(opts.IdleTimeout)
+		r.Use(idle.middleware(opts.APIPrefix))
+	}
 	r.Use(cors.Handler(cors.Options{
 		AllowedOrigins:   []string{"*"}, // @todo be more specific
 		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
@@ -94,55 +245,101 @@ func Run(ctx context.Context, app launchr.App, opts *RunOptions) error {
 	}))
 	store := &launchrServer{
 		ctx:          ctx,
-		baseURL:      opts.BaseURL(),
+		public:       public,
 		apiPrefix:    opts.APIPrefix,
 		customize:    opts.FrontendCustomize,
 		logsDirPath:  opts.LogsDirPath,
 		uiSchemaBase: opts.DefaultUISchema,
 		app:          app,
 		stateMngr:    NewStateManager(),
+		history:      history,
+		events:       newEventHub(),
+		devOrigin:    originHost(opts.ProxyClient),
+		access:       access,
+		audit:        audit,
+		metrics:      metrics,
+		tracing:      tracing,
+		startedAt:    time.Now(),
 	}
 	store.SetLogger(opts.Log())
 	store.SetTerm(opts.Term())
 	app.GetService(&store.actionMngr)
 	app.GetService(&store.cfg)
+	r.Use(store.auditMiddleware)
+	var auth *authenticator
+	if len(opts.AuthTokens) > 0 {
+		auth = &authenticator{tokens: opts.AuthTokens, secure: opts.IsTLS(), public: public}
+		if opts.LoginCode != nil {
+			auth.code, auth.codeExpires = opts.LoginCode, time.Now().Add(loginCodeTTL)
+		}
+		r.Use(auth.middleware(opts.APIPrefix))
+	}
 
 	// Provide Swagger UI.
 	if opts.SwaggerUIFS != nil {
-		serveSwaggerUI(swagger, r, opts)
+		serveSwaggerUI(swagger, r, opts, public)
+	}
+
+	if auth != nil {
+		r.Get(loginPath, auth.loginHandler)
+		r.Post(loginPath, auth.loginHandler)
+		r.Post(logoutPath, auth.logoutHandler)
 	}
 
 	r.HandleFunc("/ws", wsHandler(store))
+	r.Handle(metricsPath, metrics.handler())
 
 	// Serve frontend files.
-	r.HandleFunc("/*", spaHandler(opts))
-
-	// Use the validation middleware to check all requests against the OpenAPI schema on Api subroutes.
-	r.Route(opts.APIPrefix, func(r chi.Router) {
-		r.Use(
-			middleware.OapiRequestValidator(swagger),
-		)
-	})
+	r.HandleFunc("/*", spaHandler(opts, public))
 
-	r.Post("/api/shutdown", func(w http.ResponseWriter, _ *http.Request) {
+	// The shutdown stops the runs of all users, only admins may request it.
+	r.Post(opts.APIPrefix+"/shutdown", func(w http.ResponseWriter, r *http.Request) {
+		if !store.access.IsAdmin(identityFromContext(r.Context())) {
+			sendError(w, http.StatusForbidden, "Server shutdown is available to admins only")
+			return
+		}
 		cancel()
 		_, _ = w.Write([]byte("Server is shutting down..."))
 	})
 
-	// Register router in openapi and start the server.
-	HandlerFromMuxWithBaseURL(store, r, opts.APIPrefix)
+	// Register router in openapi and check all requests against the OpenAPI schema.
+	// The paths of the spec are relative to the API prefix.
+	validatorSpec := *swagger
+	validatorSpec.Servers = openapi3.Servers{&openapi3.Server{URL: opts.APIPrefix}}
+	r.Group(func(r chi.Router) {
+		r.Use(middleware.OapiRequestValidatorWithOptions(&validatorSpec, &middleware.Options{
+			ErrorHandler: func(w http.ResponseWriter, message string, statusCode int) {
+				sendError(w, statusCode, message)
+			},
+			SilenceServersWarning: true,
+		}))
+		HandlerFromMuxWithBaseURL(store, r, opts.APIPrefix)
+	})
+
+	// Start the server.
+	ln := opts.Listener
+	if ln == nil {
+		ln, err = Listen(opts)
+		if err != nil {
+			return err
+		}
+	}
 	s := &http.Server{
-		Handler:           r,
+		Handler:           stripBasePath(opts.BasePath, r),
 		Addr:              opts.Addr,
 		ReadHeaderTimeout: time.Second * 30, // @todo make it configurable
 	}
 
 	// @todo remove all stopped containers when stopped
 	// @todo add special prefix for web run containers.
-	store.baseURL = opts.BaseURL()
 	if opts.SwaggerUIFS != nil {
+		apiURL := opts.BaseURL() + opts.APIPrefix
 		store.Term().Info().
-			Printfln("Swagger UI: %s\nswagger.json: %s", store.basePath()+swaggerUIPath, store.basePath()+swaggerJSONPath)
+			Printfln("Swagger UI: %s\nswagger.json: %s", apiURL+swaggerUIPath, apiURL+swaggerJSONPath)
+	}
+
+	if idle != nil {
+		go idle.watch(ctx, store, cancel)
 	}
 
 	signals := make(chan os.Signal, 1)
@@ -152,12 +349,20 @@ func Run(ctx context.Context, app launchr.App, opts *RunOptions) error {
 		sig := <-signals
 		store.Log().Debug("shutting down on signal", "signal", sig)
 		cancel()
+		// Don't wait for the running actions on the second signal.
+		sig = <-signals
+		store.Log().Debug("canceling running actions on signal", "signal", sig)
+		store.stateMngr.cancelAll()
 	}()
 
 	var errShutdown error
+	shutdownDone := make(chan struct{})
 	go func() {
+		defer close(shutdownDone)
 		<-ctx.Done()
 		store.Term().Info().Println("Shutting down...")
+		// The server keeps serving while the actions are finished, so the clients may follow them.
+		store.finishRuns(opts.Shutdown)
 		ctxShut, cancelShut := context.WithTimeout(context.Background(), time.Second*10)
 		defer cancelShut()
 		errShutdown = s.Shutdown(ctxShut)
@@ -167,9 +372,20 @@ func Run(ctx context.Context, app launchr.App, opts *RunOptions) error {
 		}
 	}()
 
-	if err = s.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+	// The connections are queued by the bound listener until they are served.
+	if opts.OnReady != nil {
+		opts.OnReady()
+	}
+	if opts.IsTLS() {
+		err = s.ServeTLS(ln, opts.TLSCertFile, opts.TLSKeyFile)
+	} else {
+		err = s.Serve(ln)
+	}
+	if !errors.Is(err, http.ErrServerClosed) {
 		return err
 	}
+	// Wait for the running requests to finish.
+	<-shutdownDone
 
 	if errShutdown != nil {
 		store.Log().Error("error on shutting down", "error", err)
@@ -179,7 +395,7 @@ func Run(ctx context.Context, app launchr.App, opts *RunOptions) error {
 	return nil
 }
 
-func spaHandler(opts *RunOptions) http.HandlerFunc {
+func spaHandler(opts *RunOptions, public *publicURL) http.HandlerFunc {
 	if opts.ProxyClient != "" {
 		opts.Log().Debug("serving assets from proxy", "proxy", opts.ProxyClient)
 		target, _ := url.Parse(opts.ProxyClient)
@@ -197,41 +413,51 @@ func spaHandler(opts *RunOptions) http.HandlerFunc {
 	fileServer := http.FileServer(http.FS(opts.ClientFS))
 	return func(w http.ResponseWriter, r *http.Request) {
 		// @todo prevent directory listing in case of missing index.html
-		f, err := opts.ClientFS.Open(strings.TrimPrefix(path.Clean(r.URL.Path), "/"))
+		name := strings.TrimPrefix(path.Clean(r.URL.Path), "/")
+		if name == "" || name == "index.html" {
+			serveIndex(w, r, opts.ClientFS, public.path(r))
+			return
+		}
+		f, err := opts.ClientFS.Open(name)
 		if err == nil {
 			defer f.Close()
 		}
 		if os.IsNotExist(err) {
-			r.URL.Path = "/"
+			// Client routes are resolved by the client.
+			serveIndex(w, r, opts.ClientFS, public.path(r))
+			return
 		}
 		fileServer.ServeHTTP(w, r)
 	}
 }
 
-func serveSwaggerUI(swagger *openapi3.T, r chi.Router, opts *RunOptions) {
+func serveSwaggerUI(swagger *openapi3.T, r chi.Router, opts *RunOptions, public *publicURL) {
 	pathUI := opts.APIPrefix + swaggerUIPath
 	r.Route(pathUI, func(r chi.Router) {
 		// @todo prevent directory listing in case of missing index.html
 		r.Handle("/*", http.StripPrefix(pathUI, http.FileServer(http.FS(opts.SwaggerUIFS))))
 	})
 	r.Get(pathUI, func(w http.ResponseWriter, r *http.Request) {
-		http.Redirect(w, r, pathUI+"/", http.StatusMovedPermanently)
+		http.Redirect(w, r, public.path(r)+pathUI+"/", http.StatusMovedPermanently)
 	})
-	// Default servers for swagger ui.
-	swagger.Servers = openapi3.Servers{
-		&openapi3.Server{
-			URL: opts.APIPrefix,
-		},
-	}
 	r.Get(opts.APIPrefix+swaggerJSONPath, func(w http.ResponseWriter, r *http.Request) {
+		// Default servers for swagger ui.
+		spec := *swagger
+		spec.Servers = openapi3.Servers{
+			&openapi3.Server{
+				URL: public.path(r) + opts.APIPrefix,
+			},
+		}
 		render.Status(r, http.StatusOK)
-		render.JSON(w, r, &swagger)
+		render.JSON(w, r, &spec)
 	})
 }
 
 var upgrader = websocket.Upgrader{
 	ReadBufferSize:  1024,
 	WriteBufferSize: 1024,
+	// The origin is checked by wsHandler before the upgrade,
+	// the default check compares it with the Host header and doesn't work behind a proxy.
 	CheckOrigin: func(_ *http.Request) bool {
 		return true
 	},
@@ -242,14 +468,45 @@ type messageType struct {
 	Action  string `json:"action"`
 }
 
+// wsConn is a websocket connection safe for concurrent writes.
+type wsConn struct {
+	ws *websocket.Conn
+	// user is the authenticated user of the connection.
+	user string
+	mx   sync.Mutex
+}
+
+func (c *wsConn) writeJSON(v any) error {
+	resp, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	return c.ws.WriteMessage(websocket.TextMessage, resp)
+}
+
 func wsHandler(l *launchrServer) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		// Browsers allow websocket connections from any page and send the session cookie with them,
+		// protect from cross-site websocket hijacking with or without authentication.
+		if !checkSameOrigin(r, l.public, l.devOrigin) {
+			sendError(w, http.StatusForbidden, "Origin is not allowed")
+			return
+		}
 		ws, err := upgrader.Upgrade(w, r, nil)
 		if err != nil {
 			l.Log().Error("failed to upgrade to websocket", "error", err)
 			return
 		}
 		defer ws.Close()
+		l.metrics.wsConnections.Inc()
+		defer l.metrics.wsConnections.Dec()
+
+		// Stop all subscriptions of the connection when it's closed.
+		ctx, cancel := context.WithCancel(l.ctx)
+		defer cancel()
+		conn := &wsConn{ws: ws, user: identityFromContext(r.Context())}
 
 		var message []byte
 		for {
@@ -269,9 +526,9 @@ func wsHandler(l *launchrServer) http.HandlerFunc {
 
 			switch msg.Message {
 			case "get-processes":
-				go getProcesses(msg, ws, l)
+				go getProcesses(ctx, msg, conn, l)
 			case "get-process":
-				go getStreams(msg, ws, l)
+				go getStreams(ctx, msg, conn, l)
 			default:
 				l.Log().Info("unknown command", "command", msg.Message)
 			}
@@ -279,143 +536,143 @@ func wsHandler(l *launchrServer) http.HandlerFunc {
 	}
 }
 
-func getProcesses(msg messageType, ws *websocket.Conn, l *launchrServer) {
-	ticker := time.NewTicker(asyncTickerTime * time.Second)
-	defer ticker.Stop()
-
-	// TODO: replace that code with some listener which
-	// will send messages when action started or finished instead of ticker
-
-	for range ticker.C {
-
-		anyProccessRunning := false
-
-		runningActions := l.actionMngr.RunInfoByAction(msg.Action)
-
-		if len(runningActions) == 0 {
-			break
-		}
-
-		sort.Slice(runningActions, func(i, j int) bool {
-			return runningActions[i].ID < runningActions[j].ID
-		})
+func isRunActive(status string) bool {
+	return status == string(ActionRunStatusCreated) || status == statusRunning
+}
 
-		msgAllProcesses := map[string]interface{}{
-			"channel":   "processes",
-			"message":   "send-processes",
-			"action":    msg.Action,
-			"processes": runningActions,
-		}
+// getProcesses sends the runs of the action every time their state changes until all runs are finished.
+func getProcesses(ctx context.Context, msg messageType, conn *wsConn, l *launchrServer) {
+	if !l.access.Allowed(conn.user, PermissionView, msg.Action) {
+		return
+	}
+	sub := l.events.Subscribe(func(ev runEvent) bool {
+		return ev.ActionID == msg.Action && ev.Type != runEventOutput
+	})
+	defer l.events.Unsubscribe(sub)
 
-		resp, err := json.Marshal(msgAllProcesses)
-		if err != nil {
-			l.Log().Error("error on marshaling the response", "error", err)
+	for sendProcesses(msg, conn, l) {
+		select {
+		case <-ctx.Done():
 			return
+		case <-sub.Notify():
+			// Only the fact of the change is important, the state is read from the manager.
+			sub.Events()
 		}
+	}
+}
 
-		l.wsMutex.Lock()
-		if writeErr := ws.WriteMessage(websocket.TextMessage, resp); writeErr != nil {
-			l.Log().Error("error on writing ws all processes", "error", writeErr)
-		}
-		l.wsMutex.Unlock()
+// sendProcesses sends the current runs of the action. Returns false when there are no active runs.
+func sendProcesses(msg messageType, conn *wsConn, l *launchrServer) bool {
+	runningActions := l.actionMngr.RunInfoByAction(msg.Action)
+	if len(runningActions) == 0 {
+		return false
+	}
 
-		for _, ri := range runningActions {
-			if ri.Status == statusRunning {
-				anyProccessRunning = true
-			}
-		}
+	sort.Slice(runningActions, func(i, j int) bool {
+		return runningActions[i].ID < runningActions[j].ID
+	})
 
-		msgFinished := map[string]interface{}{
-			"channel":   "processes",
-			"message":   "send-processes-finished",
-			"action":    msg.Action,
-			"processes": runningActions,
-		}
+	msgAllProcesses := map[string]interface{}{
+		"channel":   "processes",
+		"message":   "send-processes",
+		"action":    msg.Action,
+		"processes": runningActions,
+	}
+	if err := conn.writeJSON(msgAllProcesses); err != nil {
+		l.Log().Error("error on writing ws all processes", "error", err)
+		return false
+	}
 
-		resp, err = json.Marshal(msgFinished)
-		if err != nil {
-			l.Log().Error("error on marshaling the finished processes response", "error", err)
-			return
+	for _, ri := range runningActions {
+		if isRunActive(ri.Status) {
+			return true
 		}
+	}
 
-		if !anyProccessRunning {
-			l.wsMutex.Lock()
-			if err = ws.WriteMessage(websocket.TextMessage, resp); err != nil {
-				l.Log().Error("error on writing ws finished processes", "error", err)
-			}
-			l.wsMutex.Unlock()
-			break
-		}
+	msgFinished := map[string]interface{}{
+		"channel":   "processes",
+		"message":   "send-processes-finished",
+		"action":    msg.Action,
+		"processes": runningActions,
+	}
+	if err := conn.writeJSON(msgFinished); err != nil {
+		l.Log().Error("error on writing ws finished processes", "error", err)
 	}
+	return false
 }
 
-func getStreams(msg messageType, ws *websocket.Conn, l *launchrServer) {
-	ticker := time.NewTicker(asyncTickerTime * time.Second)
-	defer ticker.Stop()
-
-	var lastStreamData interface{}
-
-	for range ticker.C {
-		ri, _ := l.actionMngr.RunInfoByID(msg.Action)
+// getStreams sends the run output every time it changes until the run is finished.
+func getStreams(ctx context.Context, msg messageType, conn *wsConn, l *launchrServer) {
+	sub := l.events.Subscribe(func(ev runEvent) bool {
+		return ev.RunID == msg.Action
+	})
+	defer l.events.Unsubscribe(sub)
 
-		// Get the streams data
-		streams := ri.Action.Input().Streams()
-		fStreams, _ := streams.(fileStreams)
-		params := GetRunningActionStreamsParams{
-			Offset: new(int),
-			Limit:  new(int),
+	// Only the output written since the previous message is sent, the client appends it.
+	var offsets [2]int
+	for {
+		ri, ok := l.actionMngr.RunInfoByID(msg.Action)
+		if !ok || !l.access.Allowed(conn.user, PermissionView, ri.Action.ID) {
+			return
 		}
-		*params.Offset = 1
-		*params.Limit = 1
-		sd, _ := fStreams.GetStreamData(params)
-
-		lastStreamData = sd
-
-		if ri.Status != statusRunning {
-			break
+		fStreams, _ := ri.Action.Input().Streams().(fileStreams)
+
+		if !isRunActive(ri.Status) {
+			// Send the final message indicating streams have finished with the whole stream data.
+			sd, _ := readStreams(fStreams, [2]int{}, true)
+			msgFinished := map[string]interface{}{
+				"channel": "process",
+				"message": "send-process-finished",
+				"action":  msg.Action,
+				"data":    sd,
+				"status":  ri.Status,
+			}
+			if err := conn.writeJSON(msgFinished); err != nil {
+				l.Log().Error("error on writing ws finished streams", "error", err)
+			}
+			return
 		}
 
-		// Send the process data
-		msgAllProcesses := map[string]interface{}{
+		// Send the new process output.
+		var sd []*ActionRunStreamData
+		sd, offsets = readStreams(fStreams, offsets, false)
+		msgProcess := map[string]interface{}{
 			"channel": "process",
 			"message": "send-process",
 			"action":  msg.Action,
 			"data":    sd,
 			"status":  ri.Status,
 		}
-
-		resp, err := json.Marshal(msgAllProcesses)
-		if err != nil {
-			l.Log().Error("error on marshaling the response", "error", err)
+		if err := conn.writeJSON(msgProcess); err != nil {
+			l.Log().Error("error on writing ws streams", "error", err)
 			return
 		}
 
-		l.wsMutex.Lock()
-		if err = ws.WriteMessage(websocket.TextMessage, resp); err != nil {
-			l.Log().Error("error on writing ws all streams", "error", err)
+		select {
+		case <-ctx.Done():
+			return
+		case <-sub.Notify():
+			// All pending changes are sent at once with the output read since the last message.
+			sub.Events()
 		}
-		l.wsMutex.Unlock()
-	}
-
-	ri, _ := l.actionMngr.RunInfoByID(msg.Action)
-	// Send the final message indicating streams have finished with the last stream data
-	msgFinished := map[string]interface{}{
-		"channel": "process",
-		"message": "send-process-finished",
-		"action":  msg.Action,
-		"data":    lastStreamData,
-		"status":  ri.Status,
 	}
+}
 
-	finalResponse, err := json.Marshal(msgFinished)
-	if err != nil {
-		l.Log().Error("error on marshaling the finished streams response", "error", err)
-		return
+// readStreams reads the output of the streams from the offsets and returns the offsets to continue from.
+// The output of a running action is read up to the last complete character.
+func readStreams(fStreams fileStreams, offsets [2]int, finished bool) ([]*ActionRunStreamData, [2]int) {
+	result := make([]*ActionRunStreamData, 0, len(offsets))
+	if fStreams == nil {
+		return result, offsets
 	}
-
-	l.wsMutex.Lock()
-	if err = ws.WriteMessage(websocket.TextMessage, finalResponse); err != nil {
-		l.Log().Error("error on writing ws finished streams", "error", err)
+	for i, typ := range []ActionRunStreamDataType{StdOut, StdErr} {
+		t, offset := string(typ), offsets[i]
+		sd, err := fStreams.GetStreamData(GetRunningActionStreamsParams{Type: &t, Offset: &offset}, finished)
+		if err != nil || len(sd) == 0 {
+			continue
+		}
+		offsets[i] = sd[0].Offset + sd[0].Count
+		result = append(result, sd[0])
 	}
-	l.wsMutex.Unlock()
+	return result, offsets
 }
@@ -0,0 +1,127 @@
+package server
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Shutdown policies of the running actions.
+const (
+	// ShutdownPolicyDrain waits for the running actions to finish.
+	ShutdownPolicyDrain = "drain"
+	// ShutdownPolicyCancel cancels the running actions.
+	ShutdownPolicyCancel = "cancel"
+)
+
+const (
+	// DefaultShutdownTimeout is a default time to wait for the running actions on shutdown.
+	DefaultShutdownTimeout = 30 * time.Second
+	// shutdownCancelTimeout is a time for the canceled actions to stop.
+	shutdownCancelTimeout = 10 * time.Second
+)
+
+// ShutdownOptions configures what happens to the running actions when the server is stopped.
+// New runs are rejected while the server is shutting down.
+type ShutdownOptions struct {
+	// Policy is "drain" (default) or "cancel".
+	// The actions still running after the drain timeout are canceled.
+	Policy string
+	// Timeout is a time to wait for the running actions. Defaults to 30 seconds.
+	Timeout time.Duration
+}
+
+// Validate checks the options.
+func (o ShutdownOptions) Validate() error {
+	switch o.Policy {
+	case "", ShutdownPolicyDrain, ShutdownPolicyCancel:
+		return nil
+	default:
+		return fmt.Errorf("unknown shutdown policy %q, expected %q or %q", o.Policy, ShutdownPolicyDrain, ShutdownPolicyCancel)
+	}
+}
+
+// runTracker tracks the active runs to finish them on shutdown.
+type runTracker struct {
+	mx       sync.Mutex
+	wg       sync.WaitGroup
+	count    int
+	draining bool
+}
+
+// start registers a new run. It returns false if the server is shutting down.
+func (t *runTracker) start() bool {
+	t.mx.Lock()
+	defer t.mx.Unlock()
+	if t.draining {
+		return false
+	}
+	t.count++
+	t.wg.Add(1)
+	return true
+}
+
+// done marks the run finished.
+func (t *runTracker) done() {
+	t.mx.Lock()
+	t.count--
+	t.mx.Unlock()
+	t.wg.Done()
+}
+
+// active returns the number of active runs.
+func (t *runTracker) active() int {
+	t.mx.Lock()
+	defer t.mx.Unlock()
+	return t.count
+}
+
+// drain rejects new runs.
+func (t *runTracker) drain() {
+	t.mx.Lock()
+	defer t.mx.Unlock()
+	t.draining = true
+}
+
+// wait waits for the active runs to finish. It returns false on timeout.
+func (t *runTracker) wait(timeout time.Duration) bool {
+	done := make(chan struct{})
+	go func() {
+		t.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
+
+// finishRuns stops accepting new runs and finishes the active ones according to the shutdown policy.
+func (l *launchrServer) finishRuns(opts ShutdownOptions) {
+	l.runs.drain()
+	n := l.runs.active()
+	if n == 0 {
+		return
+	}
+
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = DefaultShutdownTimeout
+	}
+	if opts.Policy != ShutdownPolicyCancel {
+		l.Term().Info().Printfln("Waiting up to %s for %d running action(s) to finish...", timeout, n)
+		if l.runs.wait(timeout) {
+			return
+		}
+		l.Log().Warn("running actions didn't finish in time, canceling them", "running", l.runs.active())
+		timeout = shutdownCancelTimeout
+	}
+
+	l.Term().Info().Printfln("Canceling %d running action(s)...", l.runs.active())
+	l.stateMngr.cancelAll()
+	if !l.runs.wait(timeout) {
+		l.Log().Error("running actions didn't stop after cancel", "running", l.runs.active())
+	}
+}
@@ -0,0 +1,159 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const sseKeepAliveInterval = 15 * time.Second
+
+var errInvalidEventID = errors.New("invalid Last-Event-ID")
+
+// sseWriter writes Server-Sent Events to the response.
+type sseWriter struct {
+	w http.ResponseWriter
+	f http.Flusher
+}
+
+func (s *sseWriter) event(id, name string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(s.w, "id: %s\nevent: %s\ndata: %s\n\n", id, name, data)
+	return err
+}
+
+func (s *sseWriter) comment(text string) error {
+	_, err := fmt.Fprintf(s.w, ": %s\n\n", text)
+	return err
+}
+
+func (s *sseWriter) flush() {
+	s.f.Flush()
+}
+
+// sseEventID encodes positions in the output streams, the stream may be resumed from them.
+func sseEventID(pos [2]int) string {
+	return fmt.Sprintf("%d-%d", pos[0], pos[1])
+}
+
+func parseSSEEventID(id string) ([2]int, error) {
+	var pos [2]int
+	_, err := fmt.Sscanf(id, "%d-%d", &pos[0], &pos[1])
+	if err != nil || pos[0] < 0 || pos[1] < 0 {
+		return pos, errInvalidEventID
+	}
+	return pos, nil
+}
+
+func (l *launchrServer) GetRunningActionEvents(w http.ResponseWriter, r *http.Request, id ActionId, runID ActionRunInfoId, params GetRunningActionEventsParams) {
+	rec, ok := l.history.Get(runID)
+	if !ok || rec.ActionID != id || !l.can(r, PermissionView, id) {
+		sendError(w, http.StatusNotFound, fmt.Sprintf("action run info with id %q is not found", runID))
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		sendError(w, http.StatusInternalServerError, "Streaming is not supported")
+		return
+	}
+
+	var pos [2]int
+	if params.LastEventID != nil && *params.LastEventID != "" {
+		var err error
+		pos, err = parseSSEEventID(*params.LastEventID)
+		if err != nil {
+			sendError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	// Subscribe before reading the state to not miss changes in between.
+	sub := l.events.Subscribe(func(ev runEvent) bool {
+		return ev.RunID == runID
+	})
+	defer l.events.Unsubscribe(sub)
+
+	// Read the output from the log files, so finished runs may be streamed as well.
+	files := make([]*os.File, 0, 2)
+	for _, path := range []string{rec.Logs.StdOut, rec.Logs.StdErr} {
+		f, err := os.Open(filepath.Clean(path))
+		if err != nil {
+			l.Log().Error("Failed to open run log", "runID", runID, "error", err)
+			sendError(w, http.StatusInternalServerError, "Error reading streams")
+			return
+		}
+		defer f.Close()
+		files = append(files, f)
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	// Disable response buffering in nginx.
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	sse := &sseWriter{w: w, f: flusher}
+
+	keepAlive := time.NewTicker(sseKeepAliveInterval)
+	defer keepAlive.Stop()
+
+	var status ActionRunStatus
+	for {
+		// Get the record before reading the output, so the output of a finished run is read completely.
+		rec, _ = l.history.Get(runID)
+		rec = l.actualRunRecord(rec)
+
+		for i, typ := range []ActionRunStreamDataType{StdOut, StdErr} {
+			sd, err := readStreamChunk(files[i], typ, pos[i], -1, rec.FinishedAt != nil)
+			if err != nil {
+				l.Log().Error("Failed to read run log", "runID", runID, "error", err)
+				return
+			}
+			if sd.Count == 0 {
+				continue
+			}
+			pos[i] = sd.Offset + sd.Count
+			if err = sse.event(sseEventID(pos), "output", sd); err != nil {
+				return
+			}
+		}
+
+		if rec.Status != status {
+			status = rec.Status
+			err := sse.event(sseEventID(pos), "status", ActionRunInfo{ID: rec.ID, Status: rec.Status})
+			if err != nil {
+				return
+			}
+		}
+
+		if rec.FinishedAt != nil {
+			_ = sse.event(sseEventID(pos), "finished", rec)
+			sse.flush()
+			return
+		}
+		sse.flush()
+
+		// The stream isn't closed when the server starts shutting down, the drained runs are followed until they finish.
+		// The connection is closed by the server after the shutdown timeout.
+		select {
+		case <-r.Context().Done():
+			return
+		case <-sub.Notify():
+			// The changes are read from the log files and history.
+			sub.Events()
+		case <-keepAlive.C:
+			if err := sse.comment("keep-alive"); err != nil {
+				return
+			}
+			sse.flush()
+		}
+	}
+}
@@ -41,6 +41,15 @@ func (m *StateManager) removeActionState(id string) {
 	delete(m.actionState, id)
 }
 
+// cancelAll cancels all registered actions.
+func (m *StateManager) cancelAll() {
+	m.mx.Lock()
+	defer m.mx.Unlock()
+	for _, as := range m.actionState {
+		as.cancelSwitch()
+	}
+}
+
 func (m *StateManager) actionStateByID(id string) (*ActionState, bool) {
 	m.mx.Lock()
 	defer m.mx.Unlock()
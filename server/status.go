@@ -0,0 +1,21 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+
+	"github.com/launchrctl/launchr"
+)
+
+func (l *launchrServer) GetServerStatus(w http.ResponseWriter, _ *http.Request) {
+	status := ServerStatus{
+		Version:        launchr.Version().Version,
+		Pid:            os.Getpid(),
+		StartedAt:      l.startedAt,
+		RunningActions: l.runs.active(),
+	}
+
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(status)
+}
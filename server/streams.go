@@ -1,18 +1,20 @@
 package server
 
 import (
-	"bufio"
+	"errors"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"sync"
+	"unicode/utf8"
 
 	"github.com/launchrctl/launchr"
 	"github.com/launchrctl/launchr/pkg/action"
 )
 
 type fileStreams interface {
-	GetStreamData(GetRunningActionStreamsParams) ([]*ActionRunStreamData, error)
+	GetStreamData(params GetRunningActionStreamsParams, finished bool) ([]*ActionRunStreamData, error)
 }
 
 // webCli implements Streams interface.
@@ -26,50 +28,128 @@ func (cli *webCli) Close() (err error) {
 	return cli.Streams.Close()
 }
 
+// remove closes and deletes the files of the run that didn't start.
+func (cli *webCli) remove() {
+	for _, f := range cli.files {
+		_ = f.Close()
+		_ = os.Remove(f.Name())
+	}
+}
+
+// logs returns paths to the files where the streams are written.
+func (cli *webCli) logs() ActionRunLogs {
+	return ActionRunLogs{
+		StdOut: cli.files[0].Name(),
+		StdErr: cli.files[1].Name(),
+	}
+}
+
 // GetStreamData implements fileStreams.
-func (cli *webCli) GetStreamData(_ GetRunningActionStreamsParams) ([]*ActionRunStreamData, error) {
-	// @todo include GetRunningActionStreamsParams
-	_, err := cli.files[0].Seek(0, io.SeekStart)
-	if err != nil {
-		return nil, err
+func (cli *webCli) GetStreamData(params GetRunningActionStreamsParams, finished bool) ([]*ActionRunStreamData, error) {
+	offset, limit := 0, -1
+	if params.Offset != nil {
+		offset = *params.Offset
 	}
-	reader := bufio.NewReader(cli.files[0])
-	outData, err := io.ReadAll(reader)
-	if err != nil {
-		return nil, err
+	if params.Limit != nil {
+		limit = *params.Limit
 	}
 
-	outSd := &ActionRunStreamData{
-		Type:    StdOut,
-		Content: string(outData),
+	result := make([]*ActionRunStreamData, 0, len(cli.files))
+	for i, typ := range []ActionRunStreamDataType{StdOut, StdErr} {
+		if params.Type != nil && *params.Type != "" && *params.Type != string(typ) {
+			continue
+		}
+		sd, err := readStreamChunk(cli.files[i], typ, offset, limit, finished)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, sd)
 	}
 
-	_, err = cli.files[1].Seek(0, io.SeekStart)
+	return result, nil
+}
+
+// readStreamChunk reads up to limit bytes of the file starting from offset.
+// A negative limit reads the file until the end.
+// The file of a running action may end in the middle of a character, the rest of it isn't written yet.
+// The incomplete character is returned only when the action is finished.
+func readStreamChunk(f *os.File, typ ActionRunStreamDataType, offset, limit int, finished bool) (*ActionRunStreamData, error) {
+	stat, err := f.Stat()
 	if err != nil {
 		return nil, err
 	}
-	reader = bufio.NewReader(cli.files[1])
-	errData, err := io.ReadAll(reader)
-	if err != nil {
-		return nil, err
+
+	size := int(stat.Size())
+	offset = min(max(offset, 0), size)
+	count := size - offset
+	if limit >= 0 && limit < count {
+		count = limit
 	}
 
-	errSd := &ActionRunStreamData{
-		Type:    StdErr,
-		Content: string(errData),
+	// Use ReadAt to not move the file position used by the writer.
+	buf := make([]byte, count)
+	n, err := f.ReadAt(buf, int64(offset))
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	buf = buf[:n]
+	// Don't split a multibyte character between chunks, it will be returned with the next chunk.
+	// A chunk shorter than a character is returned as is, otherwise the reader would never move on.
+	trimmed := trimIncompleteRune(buf)
+	switch {
+	case offset+n < size && len(trimmed) > 0:
+		buf = trimmed
+	case offset+n == size && !finished:
+		buf = trimmed
 	}
 
-	result := []*ActionRunStreamData{outSd, errSd}
-	return result, nil
+	return &ActionRunStreamData{
+		Type:    typ,
+		Content: string(buf),
+		Offset:  offset,
+		Count:   len(buf),
+	}, nil
+}
+
+// trimIncompleteRune removes a trailing incomplete UTF-8 sequence.
+func trimIncompleteRune(b []byte) []byte {
+	// A UTF-8 character is at most 4 bytes long, check the start of the last one.
+	for i := len(b) - 1; i >= 0 && i >= len(b)-utf8.UTFMax; i-- {
+		if !utf8.RuneStart(b[i]) {
+			continue
+		}
+		if !utf8.FullRune(b[i:]) {
+			return b[:i]
+		}
+		break
+	}
+	return b
 }
 
 type wrappedWriter struct {
 	p ActionRunStreamDataType
 	w io.Writer
+
+	// onWrite is called with every chunk written to the stream.
+	onWrite func(sd *ActionRunStreamData)
+	offset  int
+	mx      sync.Mutex
 }
 
 func (w *wrappedWriter) Write(p []byte) (int, error) {
-	return w.w.Write(p)
+	w.mx.Lock()
+	defer w.mx.Unlock()
+	n, err := w.w.Write(p)
+	if n > 0 && w.onWrite != nil {
+		w.onWrite(&ActionRunStreamData{
+			Type:    w.p,
+			Content: string(p[:n]),
+			Offset:  w.offset,
+			Count:   n,
+		})
+	}
+	w.offset += n
+	return n, err
 }
 
 func (w *wrappedWriter) Close() error {
@@ -79,7 +159,7 @@ func (w *wrappedWriter) Close() error {
 	return nil
 }
 
-func createFileStreams(streamsDir, runId string, app launchr.App, quiet bool) (*webCli, error) {
+func createFileStreams(streamsDir, runId string, app launchr.App, quiet bool, onWrite func(sd *ActionRunStreamData)) (*webCli, error) {
 	outfile, err := os.Create(filepath.Join(streamsDir, runId+"-out.txt"))
 	if err != nil {
 		return nil, fmt.Errorf("error creating output file: %w", err)
@@ -87,22 +167,28 @@ func createFileStreams(streamsDir, runId string, app launchr.App, quiet bool) (*
 
 	errfile, err := os.Create(filepath.Join(streamsDir, runId+"-err.txt"))
 	if err != nil {
+		_ = outfile.Close()
+		_ = os.Remove(outfile.Name())
 		return nil, fmt.Errorf("error creating error file: %w", err)
 	}
 
 	// Create wrapped writers
 	out := &wrappedWriter{
-		p: StdOut,
-		w: outfile,
+		p:       StdOut,
+		w:       outfile,
+		onWrite: onWrite,
 	}
 	errWriter := &wrappedWriter{
-		p: StdErr,
-		w: errfile,
+		p:       StdErr,
+		w:       errfile,
+		onWrite: onWrite,
 	}
 
 	if quiet {
 		out.w = io.Discard
+		out.onWrite = nil
 		errWriter.w = io.Discard
+		errWriter.onWrite = nil
 	}
 
 	// Build and return webCli
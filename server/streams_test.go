@@ -0,0 +1,88 @@
+package server
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReadStreamChunk(t *testing.T) {
+	t.Parallel()
+
+	// "€" is encoded in 3 bytes.
+	euro := "€"
+	tests := []struct {
+		name     string
+		content  string
+		offset   int
+		limit    int
+		finished bool
+		exp      string
+	}{
+		{"complete content", "a" + euro, 0, -1, false, "a" + euro},
+		{"incomplete rune of a running action", "a" + euro[:2], 0, -1, false, "a"},
+		{"only incomplete rune of a running action", euro[:1], 0, -1, false, ""},
+		{"incomplete rune of a finished action", "a" + euro[:2], 0, -1, true, "a" + euro[:2]},
+		{"limit inside a rune", "a" + euro + "b", 0, 2, false, "a"},
+		{"limit inside a rune of a finished action", "a" + euro + "b", 0, 2, true, "a"},
+		{"limit shorter than a rune", euro + "b", 0, 1, false, euro[:1]},
+		{"offset", "a" + euro + "b", 1, -1, false, euro + "b"},
+		{"offset beyond the end", "a", 5, -1, false, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			f := newStreamFile(t, tt.content)
+			sd, err := readStreamChunk(f, StdOut, tt.offset, tt.limit, tt.finished)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if sd.Content != tt.exp || sd.Count != len(tt.exp) {
+				t.Fatalf("expected %q, got %q with count %d", tt.exp, sd.Content, sd.Count)
+			}
+		})
+	}
+}
+
+func TestReadStreamChunkSplitRune(t *testing.T) {
+	t.Parallel()
+
+	// The action writes a 3-byte character in two writes, the reader reads in between.
+	euro := []byte("€")
+	f := newStreamFile(t, "")
+	if _, err := f.Write(append([]byte("a"), euro[:1]...)); err != nil {
+		t.Fatal(err)
+	}
+
+	sd, err := readStreamChunk(f, StdOut, 0, -1, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sd.Content != "a" {
+		t.Fatalf("expected the incomplete rune to be held back, got %q", sd.Content)
+	}
+
+	if _, err = f.Write(euro[1:]); err != nil {
+		t.Fatal(err)
+	}
+	sd, err = readStreamChunk(f, StdOut, sd.Offset+sd.Count, -1, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sd.Content != "€" || sd.Offset != 1 {
+		t.Fatalf("expected the complete rune at offset 1, got %q at offset %d", sd.Content, sd.Offset)
+	}
+}
+
+func newStreamFile(t *testing.T, content string) *os.File {
+	t.Helper()
+	f, err := os.Create(filepath.Join(t.TempDir(), "out.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = f.Close() })
+	if _, err = f.WriteString(content); err != nil {
+		t.Fatal(err)
+	}
+	return f
+}
@@ -0,0 +1,212 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	chimw "github.com/go-chi/chi/v5/middleware"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+
+	"github.com/launchrctl/launchr/pkg/action"
+)
+
+// Supported trace exporters.
+const (
+	TracingExporterOTLP   = "otlp"
+	TracingExporterStdout = "stdout"
+	TracingExporterFile   = "file"
+)
+
+const tracerName = "github.com/launchrctl/web/server"
+
+// Span attributes of the action runs.
+const (
+	attrActionID  = attribute.Key("launchr.action.id")
+	attrRunID     = attribute.Key("launchr.run.id")
+	attrRunStatus = attribute.Key("launchr.run.status")
+)
+
+// TracingOptions configures OpenTelemetry tracing. Tracing is disabled if the exporter is not set.
+type TracingOptions struct {
+	// Exporter is one of "otlp", "stdout" or "file".
+	Exporter string
+	// Endpoint is an URL of the OTLP HTTP receiver. OTEL_EXPORTER_OTLP_* variables are used if empty.
+	Endpoint string
+	// FilePath is a file where the "file" exporter writes the spans.
+	FilePath string
+}
+
+// tracing creates spans of the requests and the action runs.
+type tracing struct {
+	tracer     trace.Tracer
+	propagator propagation.TextMapPropagator
+	shutdown   func(ctx context.Context) error
+	enabled    bool
+}
+
+func newTracing(ctx context.Context, opts TracingOptions) (*tracing, error) {
+	t := &tracing{
+		tracer:     noop.NewTracerProvider().Tracer(tracerName),
+		propagator: propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}),
+		shutdown:   func(context.Context) error { return nil },
+	}
+	if opts.Exporter == "" {
+		return t, nil
+	}
+
+	var exp sdktrace.SpanExporter
+	var closer io.Closer
+	var err error
+	switch opts.Exporter {
+	case TracingExporterOTLP:
+		var clientOpts []otlptracehttp.Option
+		if opts.Endpoint != "" {
+			clientOpts = append(clientOpts, otlptracehttp.WithEndpointURL(opts.Endpoint))
+		}
+		exp, err = otlptracehttp.New(ctx, clientOpts...)
+	case TracingExporterStdout:
+		exp, err = stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+	case TracingExporterFile:
+		if err = os.MkdirAll(filepath.Dir(opts.FilePath), 0750); err != nil {
+			return nil, fmt.Errorf("can't create traces dir: %w", err)
+		}
+		var f *os.File
+		f, err = os.OpenFile(opts.FilePath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600) //nolint G304 // Path is from config.
+		if err != nil {
+			return nil, fmt.Errorf("can't open traces file: %w", err)
+		}
+		closer = f
+		exp, err = stdouttrace.New(stdouttrace.WithWriter(f))
+	default:
+		return nil, fmt.Errorf("unknown trace exporter %q", opts.Exporter)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("can't create trace exporter: %w", err)
+	}
+
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exp),
+		sdktrace.WithResource(resource.NewSchemaless(attribute.String("service.name", "launchr-web"))),
+	)
+	t.tracer = tp.Tracer(tracerName)
+	t.enabled = true
+	t.shutdown = func(ctx context.Context) error {
+		err := tp.Shutdown(ctx)
+		if closer != nil {
+			_ = closer.Close()
+		}
+		return err
+	}
+	return t, nil
+}
+
+// middleware creates a span for every API request. The incoming trace context is continued.
+func (t *tracing) middleware(apiPrefix string, operation func(r *http.Request) string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !t.enabled || !strings.HasPrefix(r.URL.Path, apiPrefix+"/") {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			ctx := t.propagator.Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+			ctx, span := t.tracer.Start(ctx, r.Method+" "+r.URL.Path,
+				trace.WithSpanKind(trace.SpanKindServer),
+				trace.WithAttributes(
+					attribute.String("http.request.method", r.Method),
+					attribute.String("url.path", r.URL.Path),
+				),
+			)
+			defer span.End()
+
+			ww := chimw.NewWrapResponseWriter(w, r.ProtoMajor)
+			next.ServeHTTP(ww, r.WithContext(ctx))
+
+			// The route is known only after the request is handled.
+			span.SetName(r.Method + " " + operation(r))
+			code := ww.Status()
+			if code == 0 {
+				code = http.StatusOK
+			}
+			span.SetAttributes(attribute.Int("http.response.status_code", code))
+			if code >= http.StatusInternalServerError {
+				span.SetStatus(codes.Error, http.StatusText(code))
+			}
+		})
+	}
+}
+
+// startRun starts a span living until the run is finished.
+func (t *tracing) startRun(ctx context.Context, actionID, runID string) trace.Span {
+	trace.SpanFromContext(ctx).SetAttributes(attrActionID.String(actionID), attrRunID.String(runID))
+	_, span := t.tracer.Start(ctx, "run "+actionID,
+		trace.WithAttributes(attrActionID.String(actionID), attrRunID.String(runID)),
+	)
+	return span
+}
+
+// finishRunSpan ends the span of the run with the final status.
+func finishRunSpan(span trace.Span, status ActionRunStatus, runErr error) {
+	span.SetAttributes(attrRunStatus.String(string(status)))
+	if runErr != nil {
+		span.RecordError(runErr)
+		span.SetStatus(codes.Error, runErr.Error())
+	}
+	span.End()
+}
+
+// traceEnvVars are the environment variables passing the trace context to the action.
+var traceEnvVars = []string{"traceparent", "tracestate"}
+
+// setRunEnv passes the trace context of the run span to the action in TRACEPARENT and TRACESTATE
+// environment variables, so the scripts of the action may attach their spans to the same trace.
+// The variables are set in the runtime definition of the run, the process environment isn't changed.
+// Actions of the plugin runtime run in the server process and don't get the trace context.
+func (t *tracing) setRunEnv(a *action.Action, span trace.Span) {
+	if !t.enabled {
+		return
+	}
+
+	carrier := propagation.MapCarrier{}
+	t.propagator.Inject(trace.ContextWithSpan(context.Background(), span), carrier)
+
+	rt := a.RuntimeDef()
+	switch {
+	case rt == nil:
+	case rt.Shell != nil:
+		rt.Shell.Env = withTraceEnv(rt.Shell.Env, carrier)
+	case rt.Container != nil:
+		rt.Container.Env = withTraceEnv(rt.Container.Env, carrier)
+	}
+}
+
+// withTraceEnv returns a copy of the env with the trace context of the carrier.
+// The definition may be shared by the runs, the trace context of a previous run is replaced.
+func withTraceEnv(env action.EnvSlice, carrier propagation.MapCarrier) action.EnvSlice {
+	result := slices.DeleteFunc(slices.Clone(env), func(v string) bool {
+		name, _, _ := strings.Cut(v, "=")
+		return slices.ContainsFunc(traceEnvVars, func(key string) bool {
+			return strings.EqualFold(name, key)
+		})
+	})
+	for _, key := range traceEnvVars {
+		if v := carrier.Get(key); v != "" {
+			result = append(result, strings.ToUpper(key)+"="+v)
+		}
+	}
+	return result
+}
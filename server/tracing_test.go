@@ -0,0 +1,80 @@
+package server
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel/propagation"
+
+	"github.com/launchrctl/launchr/pkg/action"
+)
+
+func TestWithTraceEnv(t *testing.T) {
+	t.Parallel()
+
+	const (
+		parent1 = "00-11111111111111111111111111111111-1111111111111111-01"
+		parent2 = "00-22222222222222222222222222222222-2222222222222222-01"
+	)
+	count := func(env action.EnvSlice, name string) int {
+		n := 0
+		for _, v := range env {
+			if strings.HasPrefix(v, name+"=") {
+				n++
+			}
+		}
+		return n
+	}
+
+	tests := []struct {
+		name     string
+		env      action.EnvSlice
+		carriers []propagation.MapCarrier
+		expected action.EnvSlice
+	}{
+		{
+			"empty env",
+			nil,
+			[]propagation.MapCarrier{{"traceparent": parent1}},
+			action.EnvSlice{"TRACEPARENT=" + parent1},
+		},
+		{
+			"env of the action is kept",
+			action.EnvSlice{"FOO=bar"},
+			[]propagation.MapCarrier{{"traceparent": parent1, "tracestate": "k=v"}},
+			action.EnvSlice{"FOO=bar", "TRACEPARENT=" + parent1, "TRACESTATE=k=v"},
+		},
+		{
+			"second run replaces the context",
+			action.EnvSlice{"FOO=bar"},
+			[]propagation.MapCarrier{{"traceparent": parent1, "tracestate": "k=v"}, {"traceparent": parent2}},
+			action.EnvSlice{"FOO=bar", "TRACEPARENT=" + parent2},
+		},
+		{
+			"context set in the definition is replaced",
+			action.EnvSlice{"traceparent=stale"},
+			[]propagation.MapCarrier{{"traceparent": parent1}},
+			action.EnvSlice{"TRACEPARENT=" + parent1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			original := slices.Clone(tt.env)
+			env := tt.env
+			for _, c := range tt.carriers {
+				env = withTraceEnv(env, c)
+				if n := count(env, "TRACEPARENT"); n != 1 {
+					t.Fatalf("expected exactly one TRACEPARENT, got %d in %v", n, env)
+				}
+			}
+			if !slices.Equal(env, tt.expected) {
+				t.Errorf("expected env %v, got %v", tt.expected, env)
+			}
+			if !slices.Equal(tt.env, original) {
+				t.Errorf("the env of the definition is modified: %v", tt.env)
+			}
+		})
+	}
+}
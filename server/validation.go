@@ -0,0 +1,119 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+
+	jsv "github.com/santhosh-tekuri/jsonschema/v6"
+	"github.com/santhosh-tekuri/jsonschema/v6/kind"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+
+	"github.com/launchrctl/launchr/pkg/jsonschema"
+)
+
+const (
+	inputSchemaURL = "action-input.json"
+	// inputErrorKeywordUnknown is reported when the action rejects the input valid against the schema.
+	inputErrorKeywordUnknown = "unknown"
+)
+
+// inputFieldErrors explains which fields of the submitted form don't match the action JSON schema.
+// The full schema with runtime and persistent flags is used, so pointers match the fields of the form.
+func inputFieldErrors(schema jsonschema.Schema, params ActionRunParams) ([]InputFieldError, error) {
+	schemaDoc, err := toJSONValue(schema)
+	if err != nil {
+		return nil, err
+	}
+	inst, err := toJSONValue(map[string]any{
+		"arguments":  params.Arguments,
+		"options":    params.Options,
+		"runtime":    params.Runtime,
+		"persistent": params.Persistent,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	c := jsv.NewCompiler()
+	if err = c.AddResource(inputSchemaURL, schemaDoc); err != nil {
+		return nil, err
+	}
+	sch, err := c.Compile(inputSchemaURL)
+	if err != nil {
+		return nil, err
+	}
+
+	err = sch.Validate(inst)
+	var verr *jsv.ValidationError
+	if !errors.As(err, &verr) {
+		return nil, err
+	}
+	return collectFieldErrors(verr, message.NewPrinter(language.English), nil), nil
+}
+
+// collectFieldErrors flattens the validation error tree to the leaf errors.
+func collectFieldErrors(verr *jsv.ValidationError, p *message.Printer, result []InputFieldError) []InputFieldError {
+	if len(verr.Causes) > 0 {
+		for _, cause := range verr.Causes {
+			result = collectFieldErrors(cause, p, result)
+		}
+		return result
+	}
+
+	// Point to the missing properties, so they may be highlighted in the form.
+	if req, ok := verr.ErrorKind.(*kind.Required); ok {
+		for _, prop := range req.Missing {
+			result = append(result, InputFieldError{
+				Pointer: jsonPointer(append(slices.Clone(verr.InstanceLocation), prop)),
+				Keyword: "required",
+				Message: p.Sprintf("missing property %q", prop),
+			})
+		}
+		return result
+	}
+
+	var keyword string
+	if kw := verr.ErrorKind.KeywordPath(); len(kw) > 0 {
+		keyword = kw[len(kw)-1]
+	}
+	return append(result, InputFieldError{
+		Pointer: jsonPointer(verr.InstanceLocation),
+		Keyword: keyword,
+		Message: verr.ErrorKind.LocalizedString(p),
+	})
+}
+
+// toJSONValue converts the value to a generic JSON value the validator works with.
+func toJSONValue(v any) (any, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return jsv.UnmarshalJSON(bytes.NewReader(data))
+}
+
+// jsonPointer builds RFC 6901 JSON pointer from the path segments.
+func jsonPointer(path []string) string {
+	var b strings.Builder
+	escape := strings.NewReplacer("~", "~0", "/", "~1")
+	for _, p := range path {
+		b.WriteByte('/')
+		b.WriteString(escape.Replace(p))
+	}
+	return b.String()
+}
+
+// sendValidationError responds with a list of invalid fields.
+func sendValidationError(w http.ResponseWriter, fields []InputFieldError) {
+	w.WriteHeader(http.StatusUnprocessableEntity)
+	_ = json.NewEncoder(w).Encode(InputValidationError{
+		Code:    http.StatusUnprocessableEntity,
+		Message: "The input provided is invalid. Please check your form values and try again.",
+		Errors:  fields,
+	})
+}
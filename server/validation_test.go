@@ -0,0 +1,116 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/launchrctl/launchr/pkg/action"
+	"github.com/launchrctl/launchr/pkg/jsonschema"
+)
+
+func TestInputFieldErrors(t *testing.T) {
+	t.Parallel()
+
+	schema := jsonschema.Schema{
+		Type:     "object",
+		Required: []string{"arguments", "options"},
+		Properties: map[string]any{
+			"arguments": map[string]any{
+				"type":     "object",
+				"required": []string{"name"},
+				"properties": map[string]any{
+					"name": map[string]any{"type": "string"},
+				},
+			},
+			"options": map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"port": map[string]any{"type": "integer", "minimum": 1},
+					"env":  map[string]any{"type": "string", "enum": []string{"dev", "prod"}},
+					"a/b":  map[string]any{"type": "boolean"},
+				},
+			},
+		},
+	}
+	newParams := func(args, opts action.InputParams) ActionRunParams {
+		return ActionRunParams{Arguments: args, Options: opts}
+	}
+
+	tests := []struct {
+		name   string
+		params ActionRunParams
+		exp    []string
+	}{
+		{"valid", newParams(action.InputParams{"name": "foo"}, action.InputParams{"port": 80, "env": "dev"}), []string{}},
+		{"missing argument", newParams(action.InputParams{}, action.InputParams{}), []string{"/arguments/name required"}},
+		{"wrong type", newParams(action.InputParams{"name": 1}, action.InputParams{}), []string{"/arguments/name type"}},
+		{"below minimum", newParams(action.InputParams{"name": "foo"}, action.InputParams{"port": 0}), []string{"/options/port minimum"}},
+		{"not in enum", newParams(action.InputParams{"name": "foo"}, action.InputParams{"env": "qa"}), []string{"/options/env enum"}},
+		{"escaped pointer", newParams(action.InputParams{"name": "foo"}, action.InputParams{"a/b": "yes"}), []string{"/options/a~1b type"}},
+		{
+			"several fields",
+			newParams(action.InputParams{}, action.InputParams{"port": "80", "env": "qa"}),
+			[]string{"/arguments/name required", "/options/env enum", "/options/port type"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			fields, err := inputFieldErrors(schema, tt.params)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := make([]string, 0, len(fields))
+			for _, f := range fields {
+				if f.Message == "" {
+					t.Fatalf("expected a message for %s", f.Pointer)
+				}
+				got = append(got, f.Pointer+" "+f.Keyword)
+			}
+			slices.Sort(got)
+			if !slices.Equal(got, tt.exp) {
+				t.Fatalf("expected errors %v, got %v", tt.exp, got)
+			}
+		})
+	}
+}
+
+func TestJSONPointer(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		path []string
+		exp  string
+	}{
+		{"root", nil, ""},
+		{"nested", []string{"options", "port"}, "/options/port"},
+		{"slash", []string{"options", "a/b"}, "/options/a~1b"},
+		{"tilde", []string{"options", "a~b"}, "/options/a~0b"},
+		{"tilde and slash", []string{"~/"}, "/~0~1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := jsonPointer(tt.path); got != tt.exp {
+				t.Fatalf("expected %q, got %q", tt.exp, got)
+			}
+		})
+	}
+}
+
+func TestSendValidationError(t *testing.T) {
+	t.Parallel()
+
+	w := httptest.NewRecorder()
+	sendValidationError(w, []InputFieldError{{Pointer: "", Keyword: inputErrorKeywordUnknown, Message: "rejected"}})
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected status %d, got %d", http.StatusUnprocessableEntity, w.Code)
+	}
+	if body := w.Body.String(); !strings.Contains(body, `"keyword":"unknown"`) || !strings.Contains(body, `"pointer":""`) {
+		t.Fatalf("unexpected body %s", body)
+	}
+}
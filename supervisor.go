@@ -0,0 +1,194 @@
+package web
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/launchrctl/launchr"
+)
+
+const (
+	supervisedEnvVar = launchr.EnvVar("web_supervised")
+	crashLogFilename = "crashes.log"
+
+	// Restarts are delayed more after every crash up to the maximum.
+	supervisorMinBackoff = time.Second
+	supervisorMaxBackoff = time.Minute
+	// supervisorStableRun resets the backoff if the server was running at least that long.
+	supervisorStableRun = time.Minute
+	// supervisorMaxCrashes is a number of crashes in a row without a stable run after which the supervisor gives up.
+	supervisorMaxCrashes = 5
+	// crashTailSize is a size of the server stderr kept to report the crash, e.g. a panic.
+	crashTailSize = 64 << 10
+)
+
+func isSupervisedEnv() bool {
+	return len(supervisedEnvVar.Get()) == 1
+}
+
+// superviseWeb runs the server in a child process and restarts it when it exits abnormally.
+// It cleans up the files of the instance when it stops, the supervised server doesn't remove them.
+func superviseWeb(ctx context.Context, webOpts webFlags) error {
+	// The supervisor keeps the lock while the server is restarted, the server only updates its information.
+	lock, err := lockInstance(webOpts)
+	if err != nil {
+		return err
+	}
+	defer lock.Release()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	// Keep the log of the server that keeps crashing to find out the reason.
+	gaveUp := false
+	defer func() {
+		if !gaveUp {
+			cleanupPluginTemp(webOpts.PluginDir)
+			return
+		}
+		_ = os.Remove(filepath.Join(webOpts.PluginDir, serverInfoFilename))
+		_ = os.Remove(filepath.Join(webOpts.PluginDir, pidFile))
+	}()
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(signals)
+
+	backoff := supervisorMinBackoff
+	crashes := 0
+	for {
+		startedAt := time.Now()
+		err := runSupervisedServer(ctx, signals, lock)
+		if err == nil || ctx.Err() != nil {
+			launchr.Log().Info("supervised server stopped")
+			return nil
+		}
+
+		// The server is gone without cleanup, it's not reachable anymore.
+		_ = os.Remove(filepath.Join(webOpts.PluginDir, serverInfoFilename))
+		crashes++
+		if time.Since(startedAt) >= supervisorStableRun {
+			crashes, backoff = 1, supervisorMinBackoff
+		}
+		launchr.Log().Error("supervised server crashed", "error", err, "crashes", crashes, "restart_in", backoff.String())
+		if errRec := recordCrash(webOpts.DataDir, err); errRec != nil {
+			launchr.Log().Error("failed to record crash", "error", errRec)
+		}
+		if crashes >= supervisorMaxCrashes {
+			launchr.Log().Error("supervised server keeps crashing, giving up", "crashes", crashes)
+			gaveUp = true
+			return fmt.Errorf("server crashed %d times in a row, giving up: %w", crashes, err)
+		}
+
+		select {
+		case <-time.After(backoff):
+		case <-signals:
+			return nil
+		}
+		backoff = min(backoff*2, supervisorMaxBackoff)
+	}
+}
+
+// crashError describes an abnormal exit of the server process.
+type crashError struct {
+	err    error
+	stderr string
+}
+
+func (e *crashError) Error() string {
+	return e.err.Error()
+}
+
+func (e *crashError) Unwrap() error {
+	return e.err
+}
+
+// runSupervisedServer starts the server process and waits for it.
+// The signals are passed to the server to stop it gracefully.
+func runSupervisedServer(ctx context.Context, signals <-chan os.Signal, lock *instanceLock) error {
+	stderr := &tailBuffer{max: crashTailSize}
+	command := exec.Command(os.Args[0], os.Args[1:]...) //nolint G204
+	command.Env = append(os.Environ(), supervisedEnvVar.EnvString("1"))
+	shareLock(command, lock)
+	command.Stderr = stderr
+	if err := command.Start(); err != nil {
+		return fmt.Errorf("failed to start the server process: %w", err)
+	}
+	launchr.Log().Info("supervised server started", "pid", command.Process.Pid)
+
+	done := make(chan error, 1)
+	go func() {
+		done <- command.Wait()
+	}()
+
+	stopping := false
+	for {
+		select {
+		case err := <-done:
+			if err != nil && stopping {
+				launchr.Log().Warn("supervised server stopped with error", "error", err)
+				return nil
+			}
+			if err != nil {
+				return &crashError{err: err, stderr: stderr.String()}
+			}
+			return nil
+		case sig := <-signals:
+			launchr.Log().Info("stopping supervised server", "signal", sig)
+			stopping = true
+			_ = interruptProcess(command.Process.Pid)
+		case <-ctx.Done():
+			_ = interruptProcess(command.Process.Pid)
+			return <-done
+		}
+	}
+}
+
+// recordCrash appends the crash report to the crash log in the data dir.
+func recordCrash(dataDir string, crashErr error) error {
+	if err := os.MkdirAll(dataDir, 0750); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(filepath.Join(dataDir, crashLogFilename), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600) //nolint G304 // Path is clean.
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = fmt.Fprintf(f, "=== %s: %s\n", time.Now().Format(time.RFC3339), crashErr)
+	var crash *crashError
+	if err == nil && errors.As(crashErr, &crash) && crash.stderr != "" {
+		_, err = fmt.Fprintf(f, "%s\n", crash.stderr)
+	}
+	return err
+}
+
+// tailBuffer keeps the last written bytes.
+type tailBuffer struct {
+	max int
+	buf []byte
+	mx  sync.Mutex
+}
+
+func (b *tailBuffer) Write(p []byte) (int, error) {
+	b.mx.Lock()
+	defer b.mx.Unlock()
+	b.buf = append(b.buf, p...)
+	if len(b.buf) > b.max {
+		b.buf = b.buf[len(b.buf)-b.max:]
+	}
+	return len(p), nil
+}
+
+func (b *tailBuffer) String() string {
+	b.mx.Lock()
+	defer b.mx.Unlock()
+	return string(b.buf)
+}
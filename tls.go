@@ -0,0 +1,128 @@
+package web
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	selfSignedCertFile = "cert.pem"
+	selfSignedKeyFile  = "key.pem"
+
+	selfSignedValidity = 365 * 24 * time.Hour
+	// selfSignedRenewBefore renews the certificate a bit earlier than it expires.
+	selfSignedRenewBefore = 7 * 24 * time.Hour
+)
+
+// ensureSelfSignedCert returns a cached self-signed certificate from the dir or generates a new one.
+// The certificate is valid for localhost, the host name and the listened host.
+func ensureSelfSignedCert(dir, host string) (certFile, keyFile string, err error) {
+	certFile = filepath.Join(dir, selfSignedCertFile)
+	keyFile = filepath.Join(dir, selfSignedKeyFile)
+
+	// Addresses of all interfaces are reachable as localhost.
+	if ip := net.ParseIP(host); host == "" || ip != nil && ip.IsUnspecified() {
+		host = "localhost"
+	}
+
+	if cert, err := tls.LoadX509KeyPair(certFile, keyFile); err == nil && cert.Leaf != nil {
+		if time.Now().Add(selfSignedRenewBefore).Before(cert.Leaf.NotAfter) && cert.Leaf.VerifyHostname(host) == nil {
+			return certFile, keyFile, nil
+		}
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return "", "", fmt.Errorf("can't generate private key: %w", err)
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return "", "", fmt.Errorf("can't generate serial number: %w", err)
+	}
+
+	tpl := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{Organization: []string{"Launchr Web"}, CommonName: "localhost"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(selfSignedValidity),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		DNSNames:              []string{"localhost"},
+		IPAddresses:           []net.IP{net.IPv4(127, 0, 0, 1), net.IPv6loopback},
+	}
+	if hostname, err := os.Hostname(); err == nil && hostname != "localhost" {
+		tpl.DNSNames = append(tpl.DNSNames, hostname)
+	}
+	if tpl.VerifyHostname(host) != nil {
+		if ip := net.ParseIP(host); ip != nil {
+			tpl.IPAddresses = append(tpl.IPAddresses, ip)
+		} else {
+			tpl.DNSNames = append(tpl.DNSNames, host)
+		}
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tpl, tpl, &key.PublicKey, key)
+	if err != nil {
+		return "", "", fmt.Errorf("can't create certificate: %w", err)
+	}
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return "", "", fmt.Errorf("can't encode private key: %w", err)
+	}
+
+	if err = os.MkdirAll(dir, 0750); err != nil {
+		return "", "", fmt.Errorf("can't create certificate dir: %w", err)
+	}
+	err = os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600)
+	if err != nil {
+		return "", "", fmt.Errorf("can't store certificate: %w", err)
+	}
+	err = os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0600)
+	if err != nil {
+		return "", "", fmt.Errorf("can't store private key: %w", err)
+	}
+	return certFile, keyFile, nil
+}
+
+// healthClientTimeout limits the requests to the server, a hanging server must not block the commands.
+const healthClientTimeout = 5 * time.Second
+
+// healthClient returns a client reaching the server.
+// The server certificate is trusted in addition to the system ones, the unix socket is dialed if set.
+func healthClient(ri serverInfo) (*http.Client, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if ri.CertFile != "" {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		pem, err := os.ReadFile(filepath.Clean(ri.CertFile))
+		if err != nil {
+			return nil, fmt.Errorf("can't read certificate: %w", err)
+		}
+		pool.AppendCertsFromPEM(pem)
+		transport.TLSClientConfig = &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}
+	}
+	if ri.Socket != "" {
+		transport.DialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, "unix", ri.Socket)
+		}
+	}
+	return &http.Client{Transport: transport, Timeout: healthClientTimeout}, nil
+}
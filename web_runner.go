@@ -1,7 +1,10 @@
 package web
 
 import (
+	"bytes"
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -13,9 +16,11 @@ import (
 	"path/filepath"
 	"runtime"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/launchrctl/launchr"
+	"github.com/launchrctl/launchr/pkg/action"
 
 	"github.com/launchrctl/web/server"
 )
@@ -23,6 +28,14 @@ import (
 const (
 	backgroundEnvVar   = launchr.EnvVar("web_background")
 	serverInfoFilename = "server-info.json"
+	outLogFilename     = "out.log"
+
+	// The range of the dynamic ports to look for a free one if the default port is busy.
+	dynamicPortMin = 49152
+	dynamicPortMax = 65535
+
+	// stopTimeout is a time for the server to stop in addition to the wait for the running actions.
+	stopTimeout = 30 * time.Second
 )
 
 func isBackGroundEnv() bool {
@@ -30,61 +43,130 @@ func isBackGroundEnv() bool {
 }
 
 func (p *Plugin) runWeb(ctx context.Context, webOpts webFlags) error {
-	var err error
+	// The lock is held until the server stops, another server can't start in the meantime.
+	lock, err := lockInstance(webOpts)
+	if err != nil {
+		return err
+	}
+	defer lock.Release()
+	if !isBackGroundEnv() {
+		// The pid file may be left by a crashed background server.
+		_ = os.Remove(filepath.Join(webOpts.PluginDir, pidFile))
+	}
 
-	port := webOpts.Port
-	if !isAvailablePort(port) {
-		if webOpts.IsPortSet {
-			return fmt.Errorf("requested port %d is not available", port)
-		}
-		port, err = getAvailablePort(port)
-		if err != nil {
-			return err
-		}
+	li := lockInfo{PID: os.Getpid(), Socket: webOpts.Socket, StartedAt: time.Now()}
+	li.WorkDir, _ = os.Getwd()
+	network, addr := server.NetworkUnix, webOpts.Socket
+	if webOpts.Socket == "" {
+		network, addr = "tcp", net.JoinHostPort(webOpts.Host, strconv.Itoa(webOpts.Port))
 	}
 
 	serverOpts := &server.RunOptions{
-		Addr:              fmt.Sprintf(":%d", port), // @todo use proper addr
+		Addr:              addr,
+		Network:           network,
 		APIPrefix:         APIPrefix,
+		BasePath:          webOpts.BasePath,
+		PublicURL:         webOpts.PublicURL,
+		TrustedProxies:    webOpts.TrustedProxies,
 		ProxyClient:       webOpts.ProxyClient,
 		ClientFS:          GetClientAssetsFS(),
 		SwaggerUIFS:       GetSwaggerUIAssetsFS(),
 		FrontendCustomize: webOpts.FrontendCustomize,
 		DefaultUISchema:   webOpts.DefaultUISchema,
-		LogsDirPath:       filepath.Join(webOpts.PluginDir, "logs"),
+		LogsDirPath:       filepath.Join(webOpts.DataDir, "logs"),
+		HistoryDirPath:    filepath.Join(webOpts.DataDir, "runs"),
+		AuthTokens:        webOpts.AuthTokens,
+		Access:            webOpts.Access,
+		Audit:             webOpts.Audit,
+		Tracing:           webOpts.Tracing,
+		Shutdown:          webOpts.Shutdown,
+		IdleTimeout:       webOpts.IdleTimeout,
+		TLSCertFile:       webOpts.TLSCertFile,
+		TLSKeyFile:        webOpts.TLSKeyFile,
 	}
 	serverOpts.SetLogger(webOpts.Log())
 	serverOpts.SetTerm(webOpts.Term())
-	go func() {
-		time.Sleep(time.Second)
-		err := openInBrowserWhenReady(serverOpts.BaseURL())
-		if err != nil {
-			launchr.Term().Error().Println(err)
+
+	// The listener is bound before the server info is stored, so the info has the actual address.
+	ln, err := listenWeb(webOpts, serverOpts)
+	if err != nil {
+		return err
+	}
+	defer ln.Close()
+	serverOpts.Listener = ln
+	if tcpAddr, ok := ln.Addr().(*net.TCPAddr); ok {
+		li.Port = tcpAddr.Port
+	}
+	if err = lock.update(li); err != nil {
+		return err
+	}
+
+	ri := serverInfo{
+		URL:      serverOpts.BaseURL(),
+		Socket:   webOpts.Socket,
+		CertFile: serverOpts.TLSCertFile,
+		PID:      os.Getpid(),
+		LogsDir:  serverOpts.LogsDirPath,
+		Args:     os.Args[1:],
+		Name:     webOpts.Name,
+		WorkDir:  li.WorkDir,
+	}
+	if isBackGroundEnv() {
+		ri.OutLog = filepath.Join(webOpts.PluginDir, outLogFilename)
+	}
+	if webOpts.LoginTokenFile != "" {
+		// The URL opened in the browser logs in with a one-time code, the token stays secret.
+		code := make([]byte, 16)
+		if _, err = rand.Read(code); err != nil {
+			return fmt.Errorf("can't generate login code: %w", err)
 		}
-	}()
+		ri.LoginCode = hex.EncodeToString(code)
+		serverOpts.LoginCode = &server.LoginCode{Code: ri.LoginCode, User: defaultTokenName}
+	}
+	serverOpts.OnReady = func() {
+		go openInBrowser(ri, webOpts)
+	}
 
-	err = storeServerInfo(serverInfo{URL: serverOpts.BaseURL()}, webOpts.PluginDir)
+	err = storeServerInfo(ri, webOpts.PluginDir)
 	if err != nil {
 		return err
 	}
-	defer cleanupPluginTemp(webOpts.PluginDir)
+	// The supervisor restarts the server with the same files and removes them when it stops.
+	if !isSupervisedEnv() {
+		defer cleanupPluginTemp(webOpts.PluginDir)
+	}
 	return server.Run(ctx, p.app, serverOpts)
 }
 
-func (p *Plugin) runBackgroundWeb(ctx context.Context, flags webFlags, pidFile string) error {
+func (p *Plugin) runBackgroundWeb(ctx context.Context, flags webFlags, pidFile string, args []string) error {
 	if isBackGroundEnv() {
 		err := redirectOutputs(p.app, flags)
 		if err != nil {
 			return err
 		}
 
+		if flags.Supervise && !isSupervisedEnv() {
+			return superviseWeb(ctx, flags)
+		}
 		return p.runWeb(ctx, flags)
 	}
 
-	pid, err := runBackgroundCmd(pidFile)
+	// The lock is taken before the background process starts, it's passed to the process.
+	lock, err := lockInstance(flags)
 	if err != nil {
 		return err
 	}
+	defer lock.Release()
+	cmd, err := runBackgroundCmd(pidFile, args, lock)
+	if err != nil {
+		return err
+	}
+	pid := cmd.Process.Pid
+	// The process exits early if it can't start the server, e.g. if it didn't get the lock on windows.
+	exited := make(chan error, 1)
+	go func() {
+		exited <- cmd.Wait()
+	}()
 
 	// Wait until background server is up.
 	// Check if run info created and server is reachable.
@@ -97,6 +179,12 @@ func (p *Plugin) runBackgroundWeb(ctx context.Context, flags webFlags, pidFile s
 
 	for {
 		select {
+		case err = <-exited:
+			// The server info may belong to another server, only the own pid file is removed.
+			if filePid, _ := readPidFile(pidFile); filePid == pid {
+				_ = os.Remove(pidFile)
+			}
+			return fmt.Errorf("the background process exited before the server started: %v\nSee the output with \"web logs\"", err)
 		case <-timeout:
 			// Kill existing process
 			_ = killProcess(pid)
@@ -110,19 +198,24 @@ func (p *Plugin) runBackgroundWeb(ctx context.Context, flags webFlags, pidFile s
 				continue
 			}
 
-			launchr.Term().Info().Printfln("Web is running in the background (pid: %d)\nURL: %s", pid, info.URL)
+			if info.Socket != "" {
+				launchr.Term().Info().Printfln("Web is running in the background (pid: %d)\nSocket: %s", pid, info.Socket)
+				return nil
+			}
+			launchr.Term().Info().Printfln("Web is running in the background (pid: %d)\nURL: %s", pid, flags.loginURL(*info))
+			flags.printLoginHint()
 			return nil
 		}
 	}
 }
 
 func stopWeb(pidFile string, webOpts webFlags) (err error) {
-	onSuccess := "The web UI has been successfully shut down."
+	onSuccess := fmt.Sprintf("The %s has been successfully shut down.", webOpts.title())
 
 	// Try to finish the background process.
 	pid, ok := pidFileInfo(pidFile)
 	if pid != 0 && ok {
-		err = interruptProcess(pid)
+		err = stopProcess(pid, webOpts)
 		if err != nil {
 			return err
 		}
@@ -139,42 +232,303 @@ func stopWeb(pidFile string, webOpts webFlags) (err error) {
 	}
 
 	if serverRunInfo == nil || serverRunInfo.URL == "" {
-		launchr.Term().Warning().Println("There is no active Web UI that can be stopped.")
+		launchr.Term().Warning().Printfln("There is no active %s that can be stopped.", webOpts.title())
 		return nil
 	}
 
-	if err = checkHealth(serverRunInfo.URL); err == nil {
-		return fmt.Errorf("the web UI is currently running at %s\nPlease stop it through the user interface or terminate the process", serverRunInfo.URL)
+	if _, held := readInstanceLock(webOpts.PluginDir); held || checkHealth(*serverRunInfo) == nil {
+		return fmt.Errorf("the %s is currently running at %s\nPlease stop it through the user interface or terminate the process", webOpts.title(), serverRunInfo.address())
+	}
+	if serverRunInfo.Socket != "" {
+		// Remove the socket left by the crashed server.
+		_ = os.Remove(serverRunInfo.Socket)
 	}
 	cleanupPluginTemp(webOpts.PluginDir)
 	launchr.Term().Success().Println(onSuccess)
 	return nil
 }
 
-func runBackgroundCmd(pidFile string) (int, error) {
+// stopForRestart stops the background server and waits until it exits.
+// It returns the arguments to start the server again, the previous ones are reused if no options are passed.
+func stopForRestart(pidFile string, webOpts webFlags, input *action.Input) ([]string, error) {
+	args := os.Args[1:]
+	ri, err := getServerInfo(webOpts.PluginDir)
+	if err != nil {
+		return nil, err
+	}
+	if ri != nil && len(ri.Args) > 0 && !hasChangedOpts(input) {
+		args = ri.Args
+	}
+
+	pid, ok := pidFileInfo(pidFile)
+	if !ok {
+		if _, held := readInstanceLock(webOpts.PluginDir); held && ri != nil && ri.URL != "" {
+			return nil, fmt.Errorf("the web UI is running in the foreground at %s\nPlease stop it through the user interface or terminate the process", ri.address())
+		}
+		// Nothing to stop, remove leftovers of the crashed server.
+		cleanupPluginTemp(webOpts.PluginDir)
+		return args, nil
+	}
+
+	if err = stopProcess(pid, webOpts); err != nil {
+		return nil, err
+	}
+	launchr.Term().Info().Println("The web UI has been stopped, starting it again...")
+	return args, nil
+}
+
+// stopProcess interrupts the background server and waits until it exits and releases the instance lock.
+// The server waits for the running actions according to the shutdown options.
+func stopProcess(pid int, webOpts webFlags) error {
+	if err := interruptProcess(pid); err != nil {
+		return err
+	}
+	timeout := stopTimeout + webOpts.Shutdown.Timeout
+	if webOpts.Shutdown.Timeout == 0 {
+		timeout += server.DefaultShutdownTimeout
+	}
+	launchr.Term().Info().Printfln("Waiting for the %s (pid: %d) to stop...", webOpts.title(), pid)
+	if !waitProcessExit(pid, timeout) {
+		return fmt.Errorf("the %s (pid: %d) didn't stop in %s", webOpts.title(), pid, timeout)
+	}
+	return nil
+}
+
+// hasChangedOpts checks if any option is set in the command line.
+func hasChangedOpts(input *action.Input) bool {
+	for name := range input.Opts() {
+		if input.IsOptChanged(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// logsWeb prints the log of the server running in the background.
+// The log is kept after the server crashes to find out the reason.
+func logsWeb(ctx context.Context, webOpts webFlags, follow bool) error {
+	path := filepath.Join(webOpts.PluginDir, outLogFilename)
+	f, err := os.Open(path) //nolint G304 // Path is clean.
+	if os.IsNotExist(err) && !follow {
+		launchr.Term().Warning().Println("There is no server log, it's written only when the web UI runs in the background.")
+		return nil
+	}
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("can't open server log: %w", err)
+	}
+
+	var offset int64
+	if f != nil {
+		offset, err = io.Copy(launchr.Term(), f)
+		_ = f.Close()
+		if err != nil {
+			return fmt.Errorf("can't read server log: %w", err)
+		}
+	}
+	if !follow {
+		return nil
+	}
+
+	ticker := time.NewTicker(500 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			offset, err = copyLogFrom(path, offset)
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// copyLogFrom prints the log from the offset and returns the new offset.
+// The log is printed from the beginning if the file was recreated by a new server.
+func copyLogFrom(path string, offset int64) (int64, error) {
+	f, err := os.Open(path) //nolint G304 // Path is clean.
+	if os.IsNotExist(err) {
+		// The server was stopped, wait for the next one.
+		return 0, nil
+	}
+	if err != nil {
+		return offset, fmt.Errorf("can't open server log: %w", err)
+	}
+	defer f.Close()
+
+	fi, err := f.Stat()
+	if err != nil {
+		return offset, fmt.Errorf("can't read server log: %w", err)
+	}
+	if fi.Size() < offset {
+		offset = 0
+	}
+	if _, err = f.Seek(offset, io.SeekStart); err != nil {
+		return offset, fmt.Errorf("can't read server log: %w", err)
+	}
+	n, err := io.Copy(launchr.Term(), f)
+	if err != nil {
+		return offset + n, fmt.Errorf("can't read server log: %w", err)
+	}
+	return offset + n, nil
+}
+
+// webStatus is a state of the running server reported by status operation.
+type webStatus struct {
+	Running        bool       `json:"running"`
+	Healthy        bool       `json:"healthy"`
+	Background     bool       `json:"background"`
+	URL            string     `json:"url,omitempty"`
+	Socket         string     `json:"socket,omitempty"`
+	PID            int        `json:"pid,omitempty"`
+	StartedAt      *time.Time `json:"startedAt,omitempty"`
+	Uptime         string     `json:"uptime,omitempty"`
+	Version        string     `json:"version,omitempty"`
+	RunningActions *int       `json:"runningActions,omitempty"`
+	OutLog         string     `json:"outLog,omitempty"`
+	LogsDir        string     `json:"logsDir,omitempty"`
+	Error          string     `json:"error,omitempty"`
+}
+
+func statusWeb(pidFile string, webOpts webFlags, jsonOut bool) error {
+	st, err := getWebStatus(pidFile, webOpts)
+	if err != nil {
+		return err
+	}
+
+	if jsonOut {
+		out, err := json.MarshalIndent(st, "", "  ")
+		if err != nil {
+			return err
+		}
+		launchr.Term().Println(string(out))
+		return nil
+	}
+
+	if !st.Running {
+		launchr.Term().Info().Println("The web UI is not running.")
+		return nil
+	}
+	if st.Healthy {
+		launchr.Term().Success().Println("The web UI is running.")
+	} else {
+		launchr.Term().Warning().Printfln("The web UI is not responding: %s", st.Error)
+	}
+	printField := func(name, value string) {
+		if value != "" {
+			launchr.Term().Printfln("%s: %s", name, value)
+		}
+	}
+	printField("URL", st.URL)
+	printField("Socket", st.Socket)
+	if st.PID != 0 {
+		printField("PID", strconv.Itoa(st.PID))
+	}
+	printField("Uptime", st.Uptime)
+	printField("Version", st.Version)
+	if st.RunningActions != nil {
+		printField("Running actions", strconv.Itoa(*st.RunningActions))
+	}
+	printField("Server log", st.OutLog)
+	printField("Run logs", st.LogsDir)
+	return nil
+}
+
+// getWebStatus collects the state of the server from the stored info, the pid file and the server API.
+func getWebStatus(pidFile string, webOpts webFlags) (webStatus, error) {
+	var st webStatus
+	ri, err := getServerInfo(webOpts.PluginDir)
+	if err != nil {
+		return st, err
+	}
+	pid, bgRunning := pidFileInfo(pidFile)
+	if ri == nil || ri.URL == "" {
+		// The background process may be still starting.
+		st.Running, st.Background, st.PID = bgRunning, bgRunning, pid
+		return st, nil
+	}
+
+	st.Socket, st.OutLog, st.LogsDir = ri.Socket, ri.OutLog, ri.LogsDir
+	if ri.Socket == "" {
+		st.URL = ri.URL
+	}
+	st.PID = ri.PID
+	st.Background = bgRunning
+	if bgRunning {
+		st.PID = pid
+	}
+
+	status, err := fetchServerStatus(*ri, webOpts)
+	if err != nil {
+		// The server info is left by a crashed server if the lock is released.
+		_, held := readInstanceLock(webOpts.PluginDir)
+		st.Running = bgRunning || held
+		st.Error = err.Error()
+		return st, nil
+	}
+	st.Running, st.Healthy = true, true
+	st.PID = status.Pid
+	st.StartedAt = &status.StartedAt
+	st.Uptime = time.Since(status.StartedAt).Round(time.Second).String()
+	st.Version = status.Version
+	st.RunningActions = &status.RunningActions
+	return st, nil
+}
+
+// fetchServerStatus requests the status from the server API.
+func fetchServerStatus(ri serverInfo, webOpts webFlags) (*server.ServerStatus, error) {
+	client, err := healthClient(ri)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest(http.MethodGet, ri.URL+APIPrefix+"/status", nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(webOpts.AuthTokens) > 0 {
+		req.Header.Set("Authorization", "Bearer "+webOpts.AuthTokens[0].Token)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("bad response code %d", resp.StatusCode)
+	}
+	var status server.ServerStatus
+	if err = json.NewDecoder(resp.Body).Decode(&status); err != nil {
+		return nil, fmt.Errorf("can't read server status: %w", err)
+	}
+	return &status, nil
+}
+
+func runBackgroundCmd(pidFile string, args []string, lock *instanceLock) (*exec.Cmd, error) {
 	err := launchr.EnsurePath(filepath.Dir(pidFile))
 	if err != nil {
-		return 0, fmt.Errorf("cannot create tmp directory for %q", pidFile)
+		return nil, fmt.Errorf("cannot create tmp directory for %q", pidFile)
 	}
 
 	// Prepare the command to restart itself in the background
-	command := exec.Command(os.Args[0], os.Args[1:]...) //nolint G204
+	command := exec.Command(os.Args[0], args...) //nolint G204
 	command.Env = append(os.Environ(), backgroundEnvVar.EnvString("1"))
+	passLock(command, lock)
 
 	// Set platform-specific process ID
 	setSysProcAttr(command)
 
 	err = command.Start()
 	if err != nil {
-		return 0, fmt.Errorf("failed to start the process in background: %w", err)
+		return nil, fmt.Errorf("failed to start the process in background: %w", err)
 	}
 
 	err = os.WriteFile(pidFile, []byte(strconv.Itoa(command.Process.Pid)), os.FileMode(0644))
 	if err != nil {
-		return 0, fmt.Errorf("failed to write PID file: %w", err)
+		return nil, fmt.Errorf("failed to write PID file: %w", err)
 	}
 
-	return command.Process.Pid, nil
+	return command, nil
 }
 
 func redirectOutputs(app launchr.App, webOpts webFlags) error {
@@ -183,7 +537,12 @@ func redirectOutputs(app launchr.App, webOpts webFlags) error {
 		return fmt.Errorf("can't create plugin temporary directory")
 	}
 
-	outLog, err := os.Create(filepath.Join(webOpts.PluginDir, "out.log")) //nolint G304 // Path is clean.
+	// The supervised server shares the log with the supervisor.
+	flag := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+	if isSupervisedEnv() {
+		flag = os.O_CREATE | os.O_WRONLY | os.O_APPEND
+	}
+	outLog, err := os.OpenFile(filepath.Join(webOpts.PluginDir, outLogFilename), flag, 0666) //nolint G304 // Path is clean.
 	if err != nil {
 		return err
 	}
@@ -200,6 +559,32 @@ func redirectOutputs(app launchr.App, webOpts webFlags) error {
 type serverInfo struct {
 	// URL holds the server's publicly accessible URL.
 	URL string `json:"url"`
+	// Socket is a path to the unix socket the server listens on.
+	Socket string `json:"socket,omitempty"`
+	// CertFile is a certificate of the server to trust when it's served over HTTPS.
+	CertFile string `json:"certFile,omitempty"`
+	// PID is a process id of the server.
+	PID int `json:"pid,omitempty"`
+	// OutLog is a file with the output of the server running in the background.
+	OutLog string `json:"outLog,omitempty"`
+	// LogsDir is a directory with the logs of the action runs.
+	LogsDir string `json:"logsDir,omitempty"`
+	// Args are the command line arguments the server was started with.
+	Args []string `json:"args,omitempty"`
+	// Name is a name of the server instance.
+	Name string `json:"name,omitempty"`
+	// WorkDir is a working directory of the server.
+	WorkDir string `json:"workDir,omitempty"`
+	// LoginCode is a one-time code logging in the browser with the generated token.
+	LoginCode string `json:"loginCode,omitempty"`
+}
+
+// address returns where the server may be reached.
+func (ri serverInfo) address() string {
+	if ri.Socket != "" {
+		return "unix://" + ri.Socket
+	}
+	return ri.URL
 }
 
 func storeServerInfo(ri serverInfo, storePath string) error {
@@ -212,7 +597,8 @@ func storeServerInfo(ri serverInfo, storePath string) error {
 	if err != nil {
 		return err
 	}
-	err = os.WriteFile(filepath.Join(storePath, serverInfoFilename), out, os.FileMode(0640))
+	// The file has the login code, only the owner may read it.
+	err = os.WriteFile(filepath.Join(storePath, serverInfoFilename), out, os.FileMode(0600))
 	if err != nil {
 		return err
 	}
@@ -220,16 +606,68 @@ func storeServerInfo(ri serverInfo, storePath string) error {
 	return nil
 }
 
+// loginURL returns the url of the page to open logging in with the one-time code.
+func (f webFlags) loginURL(ri serverInfo) string {
+	url := ri.URL
+	if f.OpenPath != "" || ri.LoginCode != "" {
+		url += "/" + f.OpenPath
+	}
+	if ri.LoginCode != "" {
+		url += "?code=" + ri.LoginCode
+	}
+	return url
+}
+
+// printLoginHint tells where the generated token is, the login link works only once.
+func (f webFlags) printLoginHint() {
+	if f.LoginTokenFile != "" {
+		launchr.Term().Info().Printfln("The link logs in once, use the access token from %s to log in again", f.LoginTokenFile)
+	}
+}
+
+// loadOrCreateToken reads the access token from the file or generates a new one.
+func loadOrCreateToken(path string) (string, error) {
+	data, err := os.ReadFile(filepath.Clean(path))
+	if err == nil && len(bytes.TrimSpace(data)) > 0 {
+		return string(bytes.TrimSpace(data)), nil
+	}
+	if err != nil && !os.IsNotExist(err) {
+		return "", fmt.Errorf("can't read access token: %w", err)
+	}
+
+	b := make([]byte, 32)
+	if _, err = rand.Read(b); err != nil {
+		return "", fmt.Errorf("can't generate access token: %w", err)
+	}
+	token := hex.EncodeToString(b)
+	if err = os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		return "", fmt.Errorf("can't create data dir: %w", err)
+	}
+	if err = os.WriteFile(path, []byte(token), 0600); err != nil {
+		return "", fmt.Errorf("can't store access token: %w", err)
+	}
+	return token, nil
+}
+
+// cleanupPluginTemp removes the files of the instance.
+// The directory of the default instance contains the named instances and is removed only if empty.
 func cleanupPluginTemp(dir string) {
-	err := os.RemoveAll(dir)
-	if err != nil {
-		launchr.Log().Warn("error on server info cleanup", "error", err)
+	for _, name := range []string{pidFile, serverInfoFilename, outLogFilename} {
+		err := os.Remove(filepath.Join(dir, name))
+		if err != nil && !os.IsNotExist(err) {
+			launchr.Log().Warn("error on server info cleanup", "error", err)
+		}
 	}
+	_ = os.Remove(dir)
 }
 
 // checkHealth helper to check if server is available by request.
-func checkHealth(url string) error {
-	resp, err := http.Head(url) //nolint G107 // @todo URL may come from user input, potential vulnerability.
+func checkHealth(ri serverInfo) error {
+	client, err := healthClient(ri)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Head(ri.URL) //nolint G107 // @todo URL may come from user input, potential vulnerability.
 	if err != nil {
 		return err
 	}
@@ -265,89 +703,80 @@ func getServerInfo(dir string) (*serverInfo, error) {
 	return &info, nil
 }
 
-func getExistingWeb(pidFile string, pluginDir string) (string, error) {
-	if isBackGroundEnv() {
-		// The case was checked on the init step.
-		return "", nil
+// listenWeb binds the listener of the server.
+// If the default port is busy, the first free port of the dynamic range is used.
+// The port is picked by the system only if 0 is requested.
+func listenWeb(webOpts webFlags, opts *server.RunOptions) (net.Listener, error) {
+	ln, err := server.Listen(opts)
+	if err == nil {
+		return ln, nil
 	}
-
-	serverRunInfo, err := getServerInfo(pluginDir)
-	if err != nil {
-		return "", err
+	if opts.Network == server.NetworkUnix {
+		return nil, err
 	}
-	if serverRunInfo == nil || serverRunInfo.URL == "" {
-		// No server.
-		return "", nil
+	if webOpts.IsPortSet {
+		return nil, fmt.Errorf("requested address %s is not available: %w", opts.Addr, err)
 	}
-
-	if _, ok := pidFileInfo(pidFile); ok {
-		return serverRunInfo.URL, nil
+	// Other ports won't help if the host is wrong.
+	if !isAddrInUse(err) {
+		return nil, fmt.Errorf("can't listen on %s: %w", webOpts.Host, err)
 	}
 
-	if err = checkHealth(serverRunInfo.URL); err != nil {
-		return serverRunInfo.URL, fmt.Errorf("web unhealthy response: %w", err)
+	launchr.Log().Debug("default port is not available, looking for a free one", "addr", opts.Addr, "error", err)
+	for port := dynamicPortMin; port <= dynamicPortMax; port++ {
+		opts.Addr = net.JoinHostPort(webOpts.Host, strconv.Itoa(port))
+		ln, err = server.Listen(opts)
+		if err == nil {
+			return ln, nil
+		}
+		if !isAddrInUse(err) {
+			return nil, fmt.Errorf("can't listen on %s: %w", webOpts.Host, err)
+		}
 	}
-
-	return serverRunInfo.URL, nil
+	return nil, fmt.Errorf("can't find an available port on %s: %w", webOpts.Host, err)
 }
 
-func getAvailablePort(port int) (int, error) {
-	// Quick check if port available and return if yes.
-	if isAvailablePort(port) {
-		return port, nil
-	}
-
-	maxPort := 65535
-	newPort := 49152
+// errNoDisplay is returned when there is no display to open the browser on, e.g. over SSH or in a container.
+var errNoDisplay = errors.New("no display")
 
-	// Check available port from pool.
-	for !isAvailablePort(newPort) && newPort < maxPort {
-		newPort++
+// openInBrowser prints where the server may be reached and opens the browser unless it's disabled.
+func openInBrowser(ri serverInfo, webOpts webFlags) {
+	// Browsers can't open a unix socket, it's used by the clients or behind a proxy.
+	if ri.Socket != "" {
+		launchr.Term().Info().Printfln("You can reach the web server on the unix socket: %s", ri.Socket)
+		return
 	}
-
-	if newPort >= maxPort && !isAvailablePort(newPort) {
-		panic("port limit exceeded")
+	openURL := webOpts.loginURL(ri)
+	launchr.Term().Info().Printfln("You can reach the web server at this URL: %s", openURL)
+	webOpts.printLoginHint()
+	if webOpts.NoBrowser {
+		return
+	}
+	err := openBrowser(openURL, webOpts.Browser)
+	if errors.Is(err, errNoDisplay) {
+		launchr.Log().Debug("browser is not opened, there is no display")
+		return
 	}
-
-	return newPort, nil
-}
-
-func isAvailablePort(port int) bool {
-	listener, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
 	if err != nil {
-		return false
+		launchr.Log().Error("failed to open browser", "error", err)
+		launchr.Term().Warning().Printfln("Can't open the browser: %s", err)
 	}
-
-	_ = listener.Close()
-	return true
 }
 
-func openInBrowserWhenReady(url string) error {
-	// Wait until the service is healthy.
-	retries := 0
-	for err := checkHealth(url); err != nil; {
-		time.Sleep(time.Second)
-		if retries == 10 {
-			return fmt.Errorf("web is unhealthy: %w", err)
-		}
-		retries++
-		if retries == 3 {
-			launchr.Term().Info().Println("The server isn't ready yet, please standby...")
-		}
-		launchr.Log().Debug("waiting for server to start", "retries", retries)
+// openBrowser opens the url with the configured command, BROWSER variable or the system default browser.
+func openBrowser(url, command string) error {
+	if command == "" {
+		command = os.Getenv("BROWSER")
 	}
-	// Open the browser
-	launchr.Term().Info().Printfln("You can reach the web server at this URL: %s", url)
-	if err := openBrowser(url); err != nil {
-		launchr.Log().Error("failed to open browser", "error", err)
-		return fmt.Errorf("failed to open browser: %w", err)
+	if command != "" {
+		return runBrowserCommand(command, url)
 	}
-	return nil
-}
 
-func openBrowser(url string) error {
 	switch runtime.GOOS {
 	case "linux":
+		if os.Getenv("DISPLAY") == "" && os.Getenv("WAYLAND_DISPLAY") == "" {
+			return errNoDisplay
+		}
 		return exec.Command("xdg-open", url).Start()
 	case "windows":
 		return exec.Command("rundll32", "url.dll,FileProtocolHandler", url).Start()
@@ -357,3 +786,40 @@ func openBrowser(url string) error {
 		return fmt.Errorf("unsupported platform")
 	}
 }
+
+// runBrowserCommand runs the first command of the template that starts.
+// Like BROWSER variable, the template is a list of commands separated by the path list separator.
+// "%s" is replaced with the url, the url is appended if the command doesn't have it.
+func runBrowserCommand(template, url string) error {
+	var errs []error
+	for _, line := range filepath.SplitList(template) {
+		args := strings.Fields(line)
+		if len(args) == 0 {
+			continue
+		}
+		hasURL := false
+		for i, arg := range args {
+			if strings.Contains(arg, "%s") {
+				args[i] = strings.ReplaceAll(arg, "%s", url)
+				hasURL = true
+			}
+		}
+		if !hasURL {
+			args = append(args, url)
+		}
+
+		cmd := exec.Command(args[0], args[1:]...) //nolint G204 // The command is configured by the user.
+		if err := cmd.Start(); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		go func() {
+			_ = cmd.Wait()
+		}()
+		return nil
+	}
+	if len(errs) == 0 {
+		return fmt.Errorf("browser command %q is empty", template)
+	}
+	return errors.Join(errs...)
+}